@@ -0,0 +1,352 @@
+// Package client 是天气MCP服务的Go客户端，支持stdio和Streamable HTTP传输，
+// 负责initialize握手、JSON-RPC请求与响应的对应以及服务端通知的分发
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// 客户端信息
+const (
+	clientName    = "gaode-mcp-weather-client"
+	clientVersion = "1.0.0"
+)
+
+// notificationBuffer 通知通道的容量，通道已满时丢弃新的通知
+const notificationBuffer = 64
+
+// 对外暴露的MCP与天气数据类型
+type (
+	WeatherResponse      = bean.WeatherResponse
+	WeatherBatchResponse = bean.WeatherBatchResponse
+	NowcastResponse      = bean.NowcastResponse
+	CurrentConditions    = bean.CurrentConditions
+	HourlyForecast       = bean.HourlyForecast
+	Temperature          = bean.Temperature
+	Tool                 = bean.MCPTool
+	CallToolResult       = bean.MCPCallToolResult
+	InitializeResult     = bean.MCPInitializeResult
+	RPCError             = bean.JSONRPCError
+)
+
+// ErrClosed 客户端已关闭
+var ErrClosed = errors.New("MCP客户端已关闭")
+
+// Notification 服务端推送的通知，例如notifications/progress、notifications/message
+type Notification struct {
+	Method string
+	Params json.RawMessage
+}
+
+// ToolError 工具执行失败（结果中isError为true）
+type ToolError struct {
+	Tool    string
+	Message string
+}
+
+// Error 实现error接口
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("工具%s执行失败: %s", e.Tool, e.Message)
+}
+
+// transport 传输层，负责发送消息并把收到的消息交给dispatch
+type transport interface {
+	send(ctx context.Context, msg *bean.JSONRPCMessage) error
+	close() error
+}
+
+// Client MCP客户端
+type Client struct {
+	transport     transport
+	nextID        atomic.Int64
+	notifications chan *Notification
+
+	mu      sync.Mutex
+	pending map[string]chan *bean.JSONRPCMessage
+	closed  bool
+	err     error
+	once    sync.Once
+}
+
+// newClient 创建客户端，transport在创建后通过dispatch投递收到的消息
+func newClient() *Client {
+	return &Client{
+		notifications: make(chan *Notification, notificationBuffer),
+		pending:       make(map[string]chan *bean.JSONRPCMessage),
+	}
+}
+
+// Notifications 返回服务端通知的通道，客户端关闭后通道随之关闭
+func (c *Client) Notifications() <-chan *Notification {
+	return c.notifications
+}
+
+// Initialize 完成initialize握手并发送notifications/initialized，其他方法需在此之后调用
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	var result InitializeResult
+	err := c.call(ctx, "initialize", bean.MCPInitializeParams{
+		ProtocolVersion: bean.MCPProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo: bean.MCPImplementation{
+			Name:    clientName,
+			Version: clientVersion,
+		},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if h, ok := c.transport.(interface{ initialized(version string) }); ok {
+		h.initialized(result.ProtocolVersion)
+	}
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Ping 检查服务端是否可用
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, "ping", nil, nil)
+}
+
+// SetLogLevel 设置服务端通过notifications/message推送日志的最低级别，例如debug、info、warning
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	return c.call(ctx, "logging/setLevel", bean.MCPSetLevelParams{Level: level}, nil)
+}
+
+// ListTools 列出服务端提供的工具
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var result bean.MCPListToolsResult
+	if err := c.call(ctx, "tools/list", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// CallTool 调用工具，工具执行失败时返回*ToolError
+func (c *Client) CallTool(ctx context.Context, name string, arguments interface{}) (*CallToolResult, error) {
+	args, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("参数序列化失败: %w", err)
+	}
+
+	var result CallToolResult
+	if err := c.call(ctx, "tools/call", bean.MCPCallToolParams{Name: name, Arguments: args}, &result); err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		texts := make([]string, 0, len(result.Content))
+		for _, content := range result.Content {
+			texts = append(texts, content.Text)
+		}
+		return &result, &ToolError{Tool: name, Message: strings.Join(texts, "\n")}
+	}
+	return &result, nil
+}
+
+// Weather 调用weather工具查询天气
+func (c *Client) Weather(ctx context.Context, location string) (*WeatherResponse, error) {
+	var response WeatherResponse
+	if err := c.callStructured(ctx, "weather", bean.WeatherMCPRequest{Location: location}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// WeatherAt 调用weather工具按经纬度查询天气，响应的Region为坐标所在的行政区划
+func (c *Client) WeatherAt(ctx context.Context, lat, lon float64) (*WeatherResponse, error) {
+	var response WeatherResponse
+	if err := c.callStructured(ctx, "weather", bean.WeatherMCPRequest{Latitude: &lat, Longitude: &lon}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// WeatherBatch 调用weather_batch工具批量查询天气
+func (c *Client) WeatherBatch(ctx context.Context, locations []string) (*WeatherBatchResponse, error) {
+	var response WeatherBatchResponse
+	if err := c.callStructured(ctx, "weather_batch", bean.WeatherBatchMCPRequest{Locations: locations}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// RainNowcast 调用rain_nowcast工具查询指定经纬度未来2小时逐分钟降水
+func (c *Client) RainNowcast(ctx context.Context, lat, lon float64) (*NowcastResponse, error) {
+	var response NowcastResponse
+	if err := c.callStructured(ctx, "rain_nowcast", bean.RainNowcastMCPRequest{Latitude: lat, Longitude: lon}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// callStructured 调用工具并将structuredContent解析到out
+func (c *Client) callStructured(ctx context.Context, name string, arguments, out interface{}) error {
+	result, err := c.CallTool(ctx, name, arguments)
+	if err != nil {
+		return err
+	}
+	if result.StructuredContent == nil {
+		return fmt.Errorf("工具%s的结果缺少structuredContent", name)
+	}
+
+	// StructuredContent解码后是map，重新编码后解析为具体类型
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析工具%s的结果失败: %w", name, err)
+	}
+	return nil
+}
+
+// Close 关闭客户端和底层传输
+func (c *Client) Close() error {
+	// 先记录关闭原因，关闭传输层后读循环报告的连接错误不会覆盖ErrClosed
+	c.shutdown(ErrClosed)
+	return c.transport.close()
+}
+
+// call 发送请求并等待响应，result为nil时忽略响应内容
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	id := json.RawMessage(strconv.FormatInt(c.nextID.Add(1), 10))
+	msg, err := bean.NewJSONRPCRequest(id, method, params)
+	if err != nil {
+		return fmt.Errorf("请求序列化失败: %w", err)
+	}
+
+	ch := make(chan *bean.JSONRPCMessage, 1)
+	c.mu.Lock()
+	if c.closed {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, string(id))
+		c.mu.Unlock()
+	}()
+
+	if err := c.transport.send(ctx, msg); err != nil {
+		if ctx.Err() != nil {
+			c.cancelRequest(ctx, id)
+			return ctx.Err()
+		}
+		return err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return c.closeErr()
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("解析%s响应失败: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		c.cancelRequest(ctx, id)
+		return ctx.Err()
+	}
+}
+
+// cancelRequest 通知服务端停止处理已取消的请求
+func (c *Client) cancelRequest(ctx context.Context, id json.RawMessage) {
+	c.notify(context.WithoutCancel(ctx), "notifications/cancelled", bean.MCPCancelledParams{
+		RequestID: id,
+		Reason:    ctx.Err().Error(),
+	})
+}
+
+// notify 发送通知
+func (c *Client) notify(ctx context.Context, method string, params interface{}) error {
+	msg, err := bean.NewJSONRPCNotification(method, params)
+	if err != nil {
+		return fmt.Errorf("通知序列化失败: %w", err)
+	}
+	return c.transport.send(ctx, msg)
+}
+
+// dispatch 处理传输层收到的消息
+func (c *Client) dispatch(msg *bean.JSONRPCMessage) {
+	switch {
+	case msg.IsResponse():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if ch, found := c.pending[string(bytes.TrimSpace(msg.ID))]; found {
+			select {
+			case ch <- msg:
+			default:
+			}
+		}
+	case msg.IsRequest():
+		go c.handleRequest(msg)
+	case msg.IsNotification():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.closed {
+			return
+		}
+		select {
+		case c.notifications <- &Notification{Method: msg.Method, Params: msg.Params}:
+		default:
+		}
+	}
+}
+
+// handleRequest 响应服务端发起的请求，只支持ping
+func (c *Client) handleRequest(msg *bean.JSONRPCMessage) {
+	var resp *bean.JSONRPCMessage
+	if msg.Method == "ping" {
+		resp = bean.NewJSONRPCResponse(msg.ID, struct{}{})
+	} else {
+		resp = bean.NewJSONRPCErrorResponse(msg.ID, bean.NewJSONRPCError(bean.JSONRPCMethodNotFound, "客户端不支持的方法: "+msg.Method))
+	}
+	c.transport.send(context.Background(), resp)
+}
+
+// shutdown 结束所有等待中的请求并关闭通知通道
+func (c *Client) shutdown(err error) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.closed = true
+		c.err = err
+		for id, ch := range c.pending {
+			close(ch)
+			delete(c.pending, id)
+		}
+		close(c.notifications)
+	})
+}
+
+// closeErr 返回客户端关闭的原因
+func (c *Client) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return ErrClosed
+}
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/handler"
+	"github.com/tung/mcp/internal/logic"
+	"github.com/tung/mcp/internal/service"
+)
+
+// testTimeout 单个测试中等待服务端的最长时间
+const testTimeout = 10 * time.Second
+
+func TestMain(m *testing.M) {
+	// 上游接口从testdata/upstream中的录制文件回放，不需要网络和真实的API密钥
+	service.SetUpstreamTransport(service.NewReplayTransport("testdata/upstream", service.ReplayOptions{}))
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newTestHandler 创建使用高德数据源的MCP处理器
+func newTestHandler(t *testing.T) handler.MCPHandler {
+	t.Helper()
+	districtService := service.NewDistrictService()
+	provider := service.NewAmapWeatherService("test", districtService, nil)
+	h := handler.NewMCPHandler(
+		logic.NewWeatherLogic(provider, nil, nil),
+		logic.NewLocationLogic(districtService),
+		nil,
+	)
+	t.Cleanup(h.Close)
+	return h
+}
+
+// newStdioTestClient 通过一对io.Pipe连接客户端和stdio模式的服务端
+func newStdioTestClient(t *testing.T) *Client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	h := newTestHandler(t)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.ServeStdio(context.Background(), serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	c := NewStdioClient(clientIn, clientOut)
+	t.Cleanup(func() {
+		c.Close()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("ServeStdio() error = %v", err)
+			}
+		case <-time.After(testTimeout):
+			t.Error("关闭客户端后stdio服务没有退出")
+		}
+	})
+	return c
+}
+
+// newHTTPTestClient 通过httptest服务连接Streamable HTTP端点
+func newHTTPTestClient(t *testing.T) *Client {
+	t.Helper()
+	router := gin.New()
+	newTestHandler(t).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	c := NewHTTPClient(server.URL+"/mcp", &HTTPOptions{HTTPClient: server.Client()})
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// testTransports 测试覆盖的传输方式
+var testTransports = []struct {
+	name      string
+	newClient func(t *testing.T) *Client
+}{
+	{"stdio", newStdioTestClient},
+	{"http", newHTTPTestClient},
+}
+
+func TestInitialize(t *testing.T) {
+	for _, tt := range testTransports {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			c := tt.newClient(t)
+
+			result, err := c.Initialize(ctx)
+			if err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			if result.ProtocolVersion != bean.MCPProtocolVersion {
+				t.Errorf("ProtocolVersion = %q, want %q", result.ProtocolVersion, bean.MCPProtocolVersion)
+			}
+			if result.ServerInfo.Name == "" {
+				t.Error("ServerInfo.Name is empty")
+			}
+			if err := c.Ping(ctx); err != nil {
+				t.Errorf("Ping() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestListTools(t *testing.T) {
+	for _, tt := range testTransports {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			c := tt.newClient(t)
+			if _, err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+
+			tools, err := c.ListTools(ctx)
+			if err != nil {
+				t.Fatalf("ListTools() error = %v", err)
+			}
+			byName := make(map[string]Tool)
+			for _, tool := range tools {
+				byName[tool.Name] = tool
+			}
+			for _, name := range []string{"weather", "weather_batch", "search_location"} {
+				tool, found := byName[name]
+				if !found {
+					t.Errorf("ListTools() missing %s", name)
+					continue
+				}
+				if tool.InputSchema == nil || tool.OutputSchema == nil {
+					t.Errorf("tool %s schemas = %v, %v", name, tool.InputSchema, tool.OutputSchema)
+				}
+			}
+			// 未配置彩云API时不提供短时降水工具
+			if _, found := byName["rain_nowcast"]; found {
+				t.Error("ListTools() includes rain_nowcast without nowcast provider")
+			}
+		})
+	}
+}
+
+func TestCallWeather(t *testing.T) {
+	for _, tt := range testTransports {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			c := tt.newClient(t)
+			if _, err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+
+			response, err := c.Weather(ctx, "北京")
+			if err != nil {
+				t.Fatalf("Weather() error = %v", err)
+			}
+			if response.LocationKey != "110000" {
+				t.Errorf("LocationKey = %q, want 110000", response.LocationKey)
+			}
+			if response.CurrentConditions.Temperature.Value != 18 || response.CurrentConditions.WeatherText != "晴" {
+				t.Errorf("CurrentConditions = %+v", response.CurrentConditions)
+			}
+			if response.HourlyForecast == nil {
+				t.Error("HourlyForecast is nil, want empty or populated slice")
+			}
+		})
+	}
+}
+
+func TestCallWeatherToolError(t *testing.T) {
+	for _, tt := range testTransports {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			c := tt.newClient(t)
+			if _, err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+
+			_, err := c.Weather(ctx, "不存在的地方")
+			var toolErr *ToolError
+			if !errors.As(err, &toolErr) || toolErr.Tool != "weather" {
+				t.Fatalf("Weather() error = %v, want *ToolError", err)
+			}
+		})
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	for _, tt := range testTransports {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			c := tt.newClient(t)
+			if _, err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			if err := c.SetLogLevel(ctx, "debug"); err != nil {
+				t.Fatalf("SetLogLevel() error = %v", err)
+			}
+
+			// 查询天气时服务端的日志以notifications/message推送
+			if _, err := c.Weather(ctx, "北京"); err != nil {
+				t.Fatalf("Weather() error = %v", err)
+			}
+			for {
+				select {
+				case n := <-c.Notifications():
+					if n.Method == "notifications/message" {
+						return
+					}
+				case <-ctx.Done():
+					t.Fatal("没有收到notifications/message")
+				}
+			}
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	for _, tt := range testTransports {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			c := tt.newClient(t)
+			if _, err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+
+			c.Close()
+			if err := c.Ping(ctx); !errors.Is(err, ErrClosed) {
+				t.Errorf("Ping() after Close error = %v, want ErrClosed", err)
+			}
+			// 关闭后通知通道随之关闭
+			select {
+			case _, ok := <-c.Notifications():
+				for ok {
+					_, ok = <-c.Notifications()
+				}
+			case <-ctx.Done():
+				t.Error("Notifications() not closed after Close")
+			}
+		})
+	}
+}
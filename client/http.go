@@ -0,0 +1,214 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// Streamable HTTP 传输相关的请求头
+const (
+	sessionHeader         = "Mcp-Session-Id"
+	protocolVersionHeader = "Mcp-Protocol-Version"
+)
+
+// HTTPOptions HTTP传输的选项
+type HTTPOptions struct {
+	// HTTPClient 发送请求使用的客户端，为nil时使用http.DefaultClient
+	HTTPClient *http.Client
+	// Header 每个请求附带的请求头，例如 Authorization: Bearer <token>
+	Header http.Header
+}
+
+// httpTransport Streamable HTTP 传输
+type httpTransport struct {
+	endpoint string
+	client   *http.Client
+	header   http.Header
+	dispatch func(msg *bean.JSONRPCMessage)
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+	streamCancel    context.CancelFunc
+}
+
+// NewHTTPClient 创建通过Streamable HTTP与服务端通信的客户端，endpoint为MCP端点，
+// 例如 http://localhost:8080/mcp
+func NewHTTPClient(endpoint string, opts *HTTPOptions) *Client {
+	if opts == nil {
+		opts = &HTTPOptions{}
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	c := newClient()
+	c.transport = &httpTransport{
+		endpoint: endpoint,
+		client:   httpClient,
+		header:   opts.Header.Clone(),
+		dispatch: c.dispatch,
+	}
+	return c
+}
+
+// initialized 握手完成后记录协议版本，并建立接收服务端推送的GET流
+func (t *httpTransport) initialized(version string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.mu.Lock()
+	t.protocolVersion = version
+	t.streamCancel = cancel
+	t.mu.Unlock()
+
+	go t.listen(ctx)
+}
+
+// send 以POST发送一条消息，请求的响应及期间的通知通过dispatch投递
+func (t *httpTransport) send(ctx context.Context, msg *bean.JSONRPCMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := t.newRequest(ctx, http.MethodPost, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求MCP服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if id := resp.Header.Get(sessionHeader); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent:
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("MCP服务返回状态码%d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		return t.readEvents(resp.Body)
+	}
+
+	var reply bean.JSONRPCMessage
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("解析MCP响应失败: %w", err)
+	}
+	t.dispatch(&reply)
+	return nil
+}
+
+// listen 通过GET建立SSE流接收与请求无关的服务端消息，服务端不支持时直接返回
+func (t *httpTransport) listen(ctx context.Context) {
+	req, err := t.newRequest(ctx, http.MethodGet, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	t.readEvents(resp.Body)
+}
+
+// readEvents 读取SSE流中的message事件
+func (t *httpTransport) readEvents(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), stdioMaxMessageSize)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				var msg bean.JSONRPCMessage
+				if err := json.Unmarshal([]byte(data.String()), &msg); err == nil {
+					t.dispatch(&msg)
+				}
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
+
+// newRequest 创建带会话和协议版本请求头的HTTP请求
+func (t *httpTransport) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.header {
+		req.Header[k] = v
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set(sessionHeader, t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set(protocolVersionHeader, t.protocolVersion)
+	}
+	return req, nil
+}
+
+// close 结束GET流并删除服务端会话
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	cancel, sessionID := t.streamCancel, t.sessionID
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if sessionID == "" {
+		return nil
+	}
+
+	req, err := t.newRequest(context.Background(), http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
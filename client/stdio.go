@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// stdioMaxMessageSize 单条消息的最大长度
+const stdioMaxMessageSize = 4 * 1024 * 1024
+
+// stdioTransport 按行分隔的JSON消息传输
+type stdioTransport struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  func() error
+}
+
+// NewStdioClient 通过一对读写流与服务端通信，通常是子进程的标准输出和标准输入
+func NewStdioClient(r io.Reader, w io.WriteCloser) *Client {
+	c := newClient()
+	t := &stdioTransport{
+		encoder: json.NewEncoder(w),
+		closer:  w.Close,
+	}
+	c.transport = t
+	go t.read(r, c)
+	return c
+}
+
+// NewCommandClient 启动服务端子进程并通过其标准输入输出通信，
+// cmd的参数需要让服务端以stdio模式运行，例如 -transport stdio
+func NewCommandClient(cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动MCP服务失败: %w", err)
+	}
+
+	c := newClient()
+	t := &stdioTransport{
+		encoder: json.NewEncoder(stdin),
+		closer: func() error {
+			// 关闭标准输入后服务端读到EOF自行退出
+			stdin.Close()
+			return cmd.Wait()
+		},
+	}
+	c.transport = t
+	go t.read(stdout, c)
+	return c, nil
+}
+
+// send 写入一条消息
+func (t *stdioTransport) send(_ context.Context, msg *bean.JSONRPCMessage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.encoder.Encode(msg)
+}
+
+// close 关闭写入流
+func (t *stdioTransport) close() error {
+	return t.closer()
+}
+
+// read 逐行读取服务端消息，流结束时关闭客户端
+func (t *stdioTransport) read(r io.Reader, c *Client) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), stdioMaxMessageSize)
+	for scanner.Scan() {
+		var msg bean.JSONRPCMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		c.dispatch(&msg)
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	c.shutdown(fmt.Errorf("MCP服务连接已断开: %w", err))
+}
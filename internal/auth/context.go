@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+// claimsKey 上下文中访问令牌声明的键
+type claimsKey struct{}
+
+// WithClaims 将已校验的访问令牌声明放入上下文
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext 取出上下文中的访问令牌声明，未启用鉴权时返回nil
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+	return claims
+}
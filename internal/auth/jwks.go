@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWKS 刷新参数
+const (
+	jwksRefreshInterval    = time.Hour   // 定期重新加载密钥的间隔
+	jwksMinRefreshInterval = time.Minute // 遇到未知kid时两次加载的最小间隔
+	jwksFetchTimeout       = 10 * time.Second
+	jwksRetryBackoff       = 5 * time.Second // 加载失败后的首次重试间隔，连续失败时翻倍
+	jwksMaxRetryBackoff    = 5 * time.Minute
+)
+
+// minRSAKeyBits RSA验证密钥的最小模数长度
+const minRSAKeyBits = 2048
+
+// jwk JSON Web Key，只包含验证签名需要的字段
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey 解析后的验证密钥
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// keySet 从文件或URL加载的JWKS，按需刷新。
+// 查找密钥只持有读锁，加载在锁外进行，同一时间只有一个加载请求
+type keySet struct {
+	source string
+	client *http.Client
+
+	mu         sync.RWMutex
+	keys       []*publicKey
+	fetchedAt  time.Time
+	failures   int           // 连续加载失败的次数
+	retryAt    time.Time     // 加载失败后，在此时间之前不再重试
+	lastErr    error         // 最近一次加载的错误
+	refreshing chan struct{} // 正在加载时非nil，加载结束后关闭
+}
+
+// newKeySet 创建JWKS，source为本地文件路径或http(s) URL
+func newKeySet(source string) *keySet {
+	return &keySet{
+		source: source,
+		client: &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+// get 按kid查找验证密钥，令牌未携带kid时只在密钥唯一时使用该密钥
+func (s *keySet) get(ctx context.Context, kid, alg string) (*publicKey, error) {
+	s.mu.RLock()
+	key := s.find(kid, alg)
+	sinceFetch := time.Since(s.fetchedAt)
+	backoff := time.Now().Before(s.retryAt)
+	lastErr := s.lastErr
+	s.mu.RUnlock()
+
+	stale := sinceFetch > jwksRefreshInterval
+	if stale || (key == nil && sinceFetch > jwksMinRefreshInterval) {
+		if backoff {
+			// 上次加载失败后暂不重试，已有的密钥仍可使用
+			if key == nil && lastErr != nil {
+				return nil, lastErr
+			}
+		} else if err := s.refresh(ctx); err != nil {
+			if key == nil {
+				return nil, err
+			}
+		} else {
+			s.mu.RLock()
+			key = s.find(kid, alg)
+			s.mu.RUnlock()
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("找不到令牌的验证密钥: kid=%s", kid)
+	}
+	return key, nil
+}
+
+// find 在已加载的密钥中查找，调用方需持有读锁
+func (s *keySet) find(kid, alg string) *publicKey {
+	var candidates []*publicKey
+	for _, k := range s.keys {
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+		if kid == "" || k.kid == kid {
+			candidates = append(candidates, k)
+		}
+	}
+	if len(candidates) != 1 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// refresh 重新加载JWKS，已有加载在进行时等待其结果而不是重复请求
+func (s *keySet) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	if done := s.refreshing; done != nil {
+		s.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.lastErr
+	}
+	done := make(chan struct{})
+	s.refreshing = done
+	s.mu.Unlock()
+
+	// 加载结果供所有等待的请求使用，不随发起请求的取消而中断，由jwksFetchTimeout限制时长
+	keys, err := s.fetch(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failures++
+		s.retryAt = time.Now().Add(retryBackoff(s.failures))
+	} else {
+		s.keys = keys
+		s.fetchedAt = time.Now()
+		s.failures = 0
+		s.retryAt = time.Time{}
+	}
+	s.lastErr = err
+	s.refreshing = nil
+	close(done)
+	return err
+}
+
+// retryBackoff 连续失败failures次后的重试间隔
+func retryBackoff(failures int) time.Duration {
+	backoff := jwksRetryBackoff
+	for i := 1; i < failures && backoff < jwksMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, jwksMaxRetryBackoff)
+}
+
+// fetch 加载并解析JWKS，跳过不支持的密钥
+func (s *keySet) fetch(ctx context.Context) ([]*publicKey, error) {
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("加载JWKS失败: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("解析JWKS失败: %w", err)
+	}
+
+	keys := make([]*publicKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// 跳过不支持的密钥，其余密钥仍可使用
+			continue
+		}
+		keys = append(keys, &publicKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	return keys, nil
+}
+
+// load 读取JWKS原始内容
+func (s *keySet) load(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(s.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// publicKey 将JWK转换为公钥，支持不短于minRSAKeyBits的RSA密钥和P-256/P-384/P-521椭圆曲线密钥
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA密钥长度%d位，至少需要%d位", n.BitLen(), minRSAKeyBits)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("无效的RSA指数")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ecdhCurve, err := ellipticCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("无效的椭圆曲线坐标长度")
+		}
+		// 借助crypto/ecdh校验点在曲线上
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("无效的椭圆曲线公钥: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型: %s", k.Kty)
+	}
+}
+
+// ellipticCurve 按JWK的crv名称返回曲线
+func ellipticCurve(name string) (elliptic.Curve, ecdh.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), ecdh.P256(), nil
+	case "P-384":
+		return elliptic.P384(), ecdh.P384(), nil
+	case "P-521":
+		return elliptic.P521(), ecdh.P521(), nil
+	default:
+		return nil, nil, fmt.Errorf("不支持的椭圆曲线: %s", name)
+	}
+}
+
+// decodeBigInt 解码base64url编码的大整数
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("空的整数值")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer 可以替换返回内容的JWKS服务，记录请求次数
+type jwksServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	body     []byte
+	status   int
+	delay    time.Duration
+	requests atomic.Int32
+}
+
+// newJWKSServer 创建返回body的JWKS服务
+func newJWKSServer(t *testing.T, body []byte) *jwksServer {
+	t.Helper()
+	s := &jwksServer{body: body, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.mu.Lock()
+		body, status, delay := s.body, s.status, s.delay
+		s.mu.Unlock()
+		time.Sleep(delay)
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// setDelay 设置响应前的等待时间
+func (s *jwksServer) setDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+}
+
+// set 替换返回的状态码和内容
+func (s *jwksServer) set(status int, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status, s.body = status, body
+}
+
+// age 将密钥的加载时间提前d，模拟时间流逝
+func (s *keySet) age(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetchedAt = s.fetchedAt.Add(-d)
+	if !s.retryAt.IsZero() {
+		s.retryAt = s.retryAt.Add(-d)
+	}
+}
+
+func TestKeySetRefreshOnUnknownKid(t *testing.T) {
+	ec256 := publicJWK(t, "old", testKeys()["ec256"].Public())
+	ec384 := publicJWK(t, "new", testKeys()["ec384"].Public())
+
+	tests := []struct {
+		name         string
+		age          time.Duration
+		wantRequests int32
+		wantFound    bool
+	}{
+		{"within min refresh interval", 0, 1, false},
+		{"after min refresh interval", 2 * jwksMinRefreshInterval, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJWKSServer(t, marshalJWKS(t, ec256))
+			keys := newKeySet(server.URL)
+			if _, err := keys.get(context.Background(), "old", "ES256"); err != nil {
+				t.Fatalf("get(old) error = %v", err)
+			}
+
+			// 签发方轮换密钥
+			server.set(http.StatusOK, marshalJWKS(t, ec256, ec384))
+			keys.age(tt.age)
+
+			key, err := keys.get(context.Background(), "new", "ES384")
+			if found := err == nil && key.kid == "new"; found != tt.wantFound {
+				t.Errorf("get(new) = %v, %v, want found %t", key, err, tt.wantFound)
+			}
+			if got := server.requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestKeySetStaleRefresh(t *testing.T) {
+	ec256 := publicJWK(t, "k1", testKeys()["ec256"].Public())
+	server := newJWKSServer(t, marshalJWKS(t, ec256))
+	keys := newKeySet(server.URL)
+	if _, err := keys.get(context.Background(), "k1", "ES256"); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	// 定期刷新失败时继续使用已加载的密钥
+	server.set(http.StatusInternalServerError, nil)
+	keys.age(2 * jwksRefreshInterval)
+	if _, err := keys.get(context.Background(), "k1", "ES256"); err != nil {
+		t.Fatalf("get() after failed refresh error = %v", err)
+	}
+	if got := server.requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestKeySetBackoffAfterFailure(t *testing.T) {
+	server := newJWKSServer(t, nil)
+	server.set(http.StatusServiceUnavailable, nil)
+	keys := newKeySet(server.URL)
+
+	for i := 0; i < 3; i++ {
+		if _, err := keys.get(context.Background(), "k1", "ES256"); err == nil || !strings.Contains(err.Error(), "加载JWKS失败") {
+			t.Fatalf("get() error = %v, want load failure", err)
+		}
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Fatalf("requests during backoff = %d, want 1", got)
+	}
+
+	// 退避时间过后重试成功
+	server.set(http.StatusOK, marshalJWKS(t, publicJWK(t, "k1", testKeys()["ec256"].Public())))
+	keys.age(jwksRetryBackoff)
+	if _, err := keys.get(context.Background(), "k1", "ES256"); err != nil {
+		t.Fatalf("get() after backoff error = %v", err)
+	}
+	if got := server.requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestKeySetConcurrentRefresh(t *testing.T) {
+	server := newJWKSServer(t, marshalJWKS(t, publicJWK(t, "k1", testKeys()["ec256"].Public())))
+	server.setDelay(100 * time.Millisecond)
+	keys := newKeySet(server.URL)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keys.get(context.Background(), "k1", "ES256")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("get() error = %v", err)
+		}
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestKeySetLookupDuringRefresh(t *testing.T) {
+	server := newJWKSServer(t, marshalJWKS(t, publicJWK(t, "k1", testKeys()["ec256"].Public())))
+	keys := newKeySet(server.URL)
+	if _, err := keys.get(context.Background(), "k1", "ES256"); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	// 未知kid触发的慢速加载不应阻塞已知密钥的查找
+	server.setDelay(time.Second)
+	keys.age(2 * jwksMinRefreshInterval)
+	go keys.get(context.Background(), "unknown", "ES256")
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := keys.get(context.Background(), "k1", "ES256"); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("get() took %v while another request was refreshing", elapsed)
+	}
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaJWK := publicJWK(t, "rsa", testKeys()["rsa"].Public())
+	ecJWK := publicJWK(t, "ec", testKeys()["ec256"].Public())
+
+	tests := []struct {
+		name    string
+		jwk     jwk
+		wantErr bool
+	}{
+		{"rsa 2048", rsaJWK, false},
+		{"rsa 1024", publicJWK(t, "weak", weakKey.Public()), true},
+		{"rsa exponent 1", jwk{Kty: "RSA", N: rsaJWK.N, E: "AQ"}, true},
+		{"rsa empty modulus", jwk{Kty: "RSA", E: rsaJWK.E}, true},
+		{"ec p-256", ecJWK, false},
+		{"ec wrong curve", jwk{Kty: "EC", Crv: "P-384", X: ecJWK.X, Y: ecJWK.Y}, true},
+		{"ec point not on curve", jwk{Kty: "EC", Crv: "P-256", X: ecJWK.X, Y: ecJWK.X}, true},
+		{"ec unsupported curve", jwk{Kty: "EC", Crv: "secp256k1", X: ecJWK.X, Y: ecJWK.Y}, true},
+		{"oct", jwk{Kty: "oct"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.jwk.publicKey(); (err != nil) != tt.wantErr {
+				t.Errorf("publicKey() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsWeakRSAKey(t *testing.T) {
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newJWKSServer(t, marshalJWKS(t, publicJWK(t, "weak", weakKey.Public())))
+	v := NewVerifier(testIssuer, testAudience, server.URL)
+
+	// JWKS中的弱密钥被跳过，用它签名的令牌找不到验证密钥
+	token := signTokenWith(t, "RS256", "weak", weakKey, validClaims(nil))
+	if _, err := v.Verify(context.Background(), token); err == nil || !strings.Contains(err.Error(), "找不到令牌的验证密钥") {
+		t.Fatalf("Verify() error = %v, want missing key", err)
+	}
+}
@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// 访问令牌的权限范围
+const (
+	ScopeWeatherRead  = "weather:read"  // 查询天气
+	ScopeWeatherBatch = "weather:batch" // 批量查询天气
+)
+
+// ecdsaCurves ECDSA签名算法对应的曲线
+var ecdsaCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+// clockSkew 校验令牌时间时允许的时钟误差
+const clockSkew = time.Minute
+
+// ErrInvalidToken 访问令牌无效，具体原因包含在包装的错误信息中
+var ErrInvalidToken = errors.New("无效的访问令牌")
+
+// Claims 访问令牌中本服务使用的声明
+type Claims struct {
+	Issuer    string
+	Subject   string
+	ClientID  string
+	Audience  []string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// HasScopes 令牌是否包含全部指定的权限范围
+func (c *Claims) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, s := range c.Scopes {
+			if s == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Verifier 访问令牌校验器接口
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Claims, error)
+}
+
+// verifier JWT访问令牌校验器实现
+type verifier struct {
+	issuer   string
+	audience string
+	keys     *keySet
+}
+
+// NewVerifier 创建新的JWT访问令牌校验器，jwks为JWKS文件路径或URL，issuer为空时不校验签发方
+func NewVerifier(issuer, audience, jwks string) Verifier {
+	return &verifier{
+		issuer:   issuer,
+		audience: audience,
+		keys:     newKeySet(jwks),
+	}
+}
+
+// jwtHeader JWT头部
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtPayload JWT载荷
+type jwtPayload struct {
+	Iss      string   `json:"iss"`
+	Sub      string   `json:"sub"`
+	Aud      strList  `json:"aud"`
+	Exp      *float64 `json:"exp"`
+	Nbf      *float64 `json:"nbf"`
+	Scope    strList  `json:"scope"`
+	Scp      strList  `json:"scp"`
+	ClientID string   `json:"client_id"`
+	Azp      string   `json:"azp"`
+}
+
+// strList 可以是单个字符串（以空格分隔）或字符串数组的声明
+type strList []string
+
+// UnmarshalJSON 解析字符串或字符串数组
+func (l *strList) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var list []string
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		*l = list
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*l = strings.Fields(s)
+	return nil
+}
+
+// Verify 校验JWT的签名、签发方、受众和有效期
+func (v *verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: 格式错误", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: 头部格式错误", ErrInvalidToken)
+	}
+	hash, ok := signatureHash(header.Alg)
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的签名算法 %s", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: 签名格式错误", ErrInvalidToken)
+	}
+	key, err := v.keys.get(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := verifySignature(header.Alg, hash, key.key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var payload jwtPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, fmt.Errorf("%w: 载荷格式错误", ErrInvalidToken)
+	}
+	return v.checkClaims(&payload)
+}
+
+// checkClaims 校验令牌声明
+func (v *verifier) checkClaims(payload *jwtPayload) (*Claims, error) {
+	now := time.Now()
+	if payload.Exp == nil {
+		return nil, fmt.Errorf("%w: 缺少exp", ErrInvalidToken)
+	}
+	expiresAt := time.Unix(int64(*payload.Exp), 0)
+	if now.After(expiresAt.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: 令牌已过期", ErrInvalidToken)
+	}
+	if payload.Nbf != nil && now.Add(clockSkew).Before(time.Unix(int64(*payload.Nbf), 0)) {
+		return nil, fmt.Errorf("%w: 令牌尚未生效", ErrInvalidToken)
+	}
+	if v.issuer != "" && payload.Iss != v.issuer {
+		return nil, fmt.Errorf("%w: 签发方不匹配", ErrInvalidToken)
+	}
+
+	audienceOK := false
+	for _, aud := range payload.Aud {
+		if aud == v.audience {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("%w: 受众不匹配", ErrInvalidToken)
+	}
+
+	clientID := payload.ClientID
+	if clientID == "" {
+		clientID = payload.Azp
+	}
+	scopes := payload.Scope
+	if len(scopes) == 0 {
+		scopes = payload.Scp
+	}
+
+	return &Claims{
+		Issuer:    payload.Iss,
+		Subject:   payload.Sub,
+		ClientID:  clientID,
+		Audience:  payload.Aud,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// decodeSegment 解码JWT的base64url JSON片段
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// signatureHash 返回签名算法使用的哈希，不支持的算法（包括none）返回false
+func signatureHash(alg string) (crypto.Hash, bool) {
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		return crypto.SHA256, true
+	case "RS384", "PS384", "ES384":
+		return crypto.SHA384, true
+	case "RS512", "PS512", "ES512":
+		return crypto.SHA512, true
+	default:
+		return 0, false
+	}
+}
+
+// verifySignature 按算法校验签名
+func verifySignature(alg string, hash crypto.Hash, key crypto.PublicKey, signed, signature []byte) error {
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("密钥类型与算法%s不匹配", alg)
+		}
+		var err error
+		if alg[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(pub, hash, digest, signature, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("签名校验失败")
+		}
+		return nil
+	default:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("密钥类型与算法%s不匹配", alg)
+		}
+		if pub.Curve.Params().Name != ecdsaCurves[alg] {
+			return fmt.Errorf("椭圆曲线与算法%s不匹配", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("签名长度错误")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("签名校验失败")
+		}
+		return nil
+	}
+}
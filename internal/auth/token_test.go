@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试使用的签发方和受众
+const (
+	testIssuer   = "https://auth.example.com"
+	testAudience = "https://weather.example.com/mcp"
+)
+
+// testKeys 测试用的签名密钥，生成RSA密钥较慢，所有测试共用
+var testKeys = sync.OnceValue(func() map[string]crypto.Signer {
+	keys := make(map[string]crypto.Signer)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	keys["rsa"] = rsaKey
+	for kid, curve := range map[string]elliptic.Curve{
+		"ec256": elliptic.P256(),
+		"ec384": elliptic.P384(),
+		"ec521": elliptic.P521(),
+	} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		keys[kid] = ecKey
+	}
+	return keys
+})
+
+// publicJWK 将公钥转换为JWK
+func publicJWK(t *testing.T, kid string, pub crypto.PublicKey) jwk {
+	t.Helper()
+	enc := base64.RawURLEncoding.EncodeToString
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return jwk{Kty: "RSA", Kid: kid, Use: "sig", N: enc(key.N.Bytes()), E: enc(big.NewInt(int64(key.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return jwk{Kty: "EC", Kid: kid, Use: "sig", Crv: key.Curve.Params().Name, X: enc(key.X.FillBytes(make([]byte, size))), Y: enc(key.Y.FillBytes(make([]byte, size)))}
+	default:
+		t.Fatalf("不支持的公钥类型 %T", pub)
+		return jwk{}
+	}
+}
+
+// marshalJWKS 将JWK序列化为JWKS
+func marshalJWKS(t *testing.T, keys ...jwk) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string][]jwk{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// writeJWKS 将所有测试密钥写入临时JWKS文件，返回文件路径
+func writeJWKS(t *testing.T) string {
+	t.Helper()
+	var keys []jwk
+	for kid, key := range testKeys() {
+		keys = append(keys, publicJWK(t, kid, key.Public()))
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, marshalJWKS(t, keys...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// encodeSegment 将值编码为JWT的base64url JSON片段
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signToken 使用kid对应的测试密钥按alg签名令牌
+func signToken(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	return signTokenWith(t, alg, kid, testKeys()[kid], claims)
+}
+
+// signTokenWith 使用指定密钥按alg签名令牌，头部携带kid
+func signTokenWith(t *testing.T, alg, kid string, signer crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+
+	hash, ok := signatureHash(alg)
+	if !ok {
+		t.Fatalf("不支持的签名算法 %s", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	var err error
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(alg, "PS") {
+			signature, err = rsa.SignPSS(rand.Reader, key, hash, digest, nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest)
+		if err == nil {
+			size := (key.Curve.Params().BitSize + 7) / 8
+			signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+		}
+	default:
+		t.Fatalf("不支持的签名密钥 %T", signer)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims 返回可以通过校验的声明，overrides中值为nil的声明会被删除
+func validClaims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":       testIssuer,
+		"sub":       "user-1",
+		"aud":       testAudience,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"scope":     "weather:read weather:batch",
+		"client_id": "client-1",
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	return claims
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	v := NewVerifier(testIssuer, testAudience, writeJWKS(t))
+
+	tests := []struct {
+		alg string
+		kid string
+	}{
+		{"RS256", "rsa"},
+		{"RS384", "rsa"},
+		{"RS512", "rsa"},
+		{"PS256", "rsa"},
+		{"PS384", "rsa"},
+		{"PS512", "rsa"},
+		{"ES256", "ec256"},
+		{"ES384", "ec384"},
+		{"ES512", "ec521"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), signToken(t, tt.alg, tt.kid, validClaims(nil)))
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "user-1" || claims.ClientID != "client-1" {
+				t.Errorf("Verify() claims = %+v", claims)
+			}
+			if !claims.HasScopes(ScopeWeatherRead, ScopeWeatherBatch) {
+				t.Errorf("Verify() scopes = %v", claims.Scopes)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgorithm(t *testing.T) {
+	v := NewVerifier(testIssuer, testAudience, writeJWKS(t))
+	payload := encodeSegment(t, validClaims(nil))
+
+	// HS256令牌以RSA公钥作为HMAC密钥签名，是典型的算法混淆攻击
+	rsaPub, err := x509.MarshalPKIXPublicKey(testKeys()["rsa"].Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsSigned := encodeSegment(t, map[string]string{"alg": "HS256", "kid": "rsa"}) + "." + payload
+	mac := hmac.New(sha256.New, rsaPub)
+	mac.Write([]byte(hsSigned))
+	hsToken := hsSigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	// 将已签名令牌的头部替换为其他算法，签名保持不变
+	reheader := func(token, alg, kid string) string {
+		parts := strings.SplitN(token, ".", 2)
+		return encodeSegment(t, map[string]string{"alg": alg, "kid": kid}) + "." + parts[1]
+	}
+
+	// 替换已签名令牌的载荷，签名保持不变
+	tamper := func(token string) string {
+		parts := strings.Split(token, ".")
+		return parts[0] + "." + encodeSegment(t, validClaims(map[string]interface{}{"sub": "admin"})) + "." + parts[2]
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"alg none", encodeSegment(t, map[string]string{"alg": "none"}) + "." + payload + "."},
+		{"alg None", encodeSegment(t, map[string]string{"alg": "None", "kid": "rsa"}) + "." + payload + "."},
+		{"HS256 with RSA public key", hsToken},
+		{"RS256 header with EC key", reheader(signToken(t, "ES256", "ec256", validClaims(nil)), "RS256", "ec256")},
+		{"ES256 header with RSA key", reheader(signToken(t, "RS256", "rsa", validClaims(nil)), "ES256", "rsa")},
+		{"ES384 header with P-256 key", reheader(signToken(t, "ES256", "ec256", validClaims(nil)), "ES384", "ec256")},
+		{"PS256 signature as RS256", reheader(signToken(t, "PS256", "rsa", validClaims(nil)), "RS256", "rsa")},
+		{"RS256 signature as RS512", reheader(signToken(t, "RS256", "rsa", validClaims(nil)), "RS512", "rsa")},
+		{"unknown kid", reheader(signToken(t, "ES256", "ec256", validClaims(nil)), "ES256", "missing")},
+		{"tampered payload", tamper(signToken(t, "RS256", "rsa", validClaims(nil)))},
+		{"malformed", "not-a-jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Verify(context.Background(), tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	v := NewVerifier(testIssuer, testAudience, writeJWKS(t))
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		overrides map[string]interface{}
+		wantErr   string
+	}{
+		{"valid", nil, ""},
+		{"audience list", map[string]interface{}{"aud": []string{"other", testAudience}}, ""},
+		{"expired within clock skew", map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}, ""},
+		{"nbf within clock skew", map[string]interface{}{"nbf": now.Add(30 * time.Second).Unix()}, ""},
+		{"scp claim", map[string]interface{}{"scope": nil, "scp": []string{ScopeWeatherRead}}, ""},
+		{"missing exp", map[string]interface{}{"exp": nil}, "缺少exp"},
+		{"expired", map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()}, "令牌已过期"},
+		{"not yet valid", map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()}, "令牌尚未生效"},
+		{"wrong issuer", map[string]interface{}{"iss": "https://evil.example.com"}, "签发方不匹配"},
+		{"missing issuer", map[string]interface{}{"iss": nil}, "签发方不匹配"},
+		{"wrong audience", map[string]interface{}{"aud": "https://other.example.com"}, "受众不匹配"},
+		{"audience list without match", map[string]interface{}{"aud": []string{"a", "b"}}, "受众不匹配"},
+		{"missing audience", map[string]interface{}{"aud": nil}, "受众不匹配"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), signToken(t, "ES256", "ec256", validClaims(tt.overrides)))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutIssuer(t *testing.T) {
+	v := NewVerifier("", testAudience, writeJWKS(t))
+	token := signToken(t, "RS256", "rsa", validClaims(map[string]interface{}{"iss": "https://any.example.com"}))
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package bean
+
+import "encoding/json"
+
+// AmapString 高德接口中的字符串字段，没有值时接口返回空数组[]而不是空字符串
+type AmapString string
+
+// UnmarshalJSON 将空数组解析为空字符串
+func (s *AmapString) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		*s = ""
+		return nil
+	}
+	*s = AmapString(value)
+	return nil
+}
+
+// AmapGeocodeResponse 高德地理编码响应
+type AmapGeocodeResponse struct {
+	Status   string        `json:"status"`   // 返回状态，1表示成功
+	Count    string        `json:"count"`    // 返回结果数目
+	Info     string        `json:"info"`     // 返回的状态信息
+	InfoCode string        `json:"infocode"` // 返回状态说明
+	Geocodes []AmapGeocode `json:"geocodes"` // 地理编码结果
+}
+
+// AmapRegeoResponse 高德逆地理编码响应
+type AmapRegeoResponse struct {
+	Status    string        `json:"status"`    // 返回状态，1表示成功
+	Info      string        `json:"info"`      // 返回的状态信息
+	InfoCode  string        `json:"infocode"`  // 返回状态说明
+	Regeocode AmapRegeocode `json:"regeocode"` // 逆地理编码结果
+}
+
+// AmapRegeocode 高德逆地理编码结果
+type AmapRegeocode struct {
+	FormattedAddress AmapString           `json:"formatted_address"` // 结构化地址
+	AddressComponent AmapAddressComponent `json:"addressComponent"`  // 地址要素
+}
+
+// AmapAddressComponent 高德逆地理编码的地址要素，坐标在国外时大部分字段为空
+type AmapAddressComponent struct {
+	Country  AmapString `json:"country"`  // 国家
+	Province AmapString `json:"province"` // 省份名
+	City     AmapString `json:"city"`     // 城市名，直辖市为空
+	Citycode AmapString `json:"citycode"` // 城市编码
+	District AmapString `json:"district"` // 区县名
+	Adcode   AmapString `json:"adcode"`   // 区域编码
+	Township AmapString `json:"township"` // 乡镇或街道
+}
+
+// AmapIPResponse 高德IP定位响应，国外或局域网IP的各字段为空
+type AmapIPResponse struct {
+	Status    string     `json:"status"`    // 返回状态，1表示成功
+	Info      string     `json:"info"`      // 返回的状态信息
+	InfoCode  string     `json:"infocode"`  // 返回状态说明
+	Province  AmapString `json:"province"`  // 省份名
+	City      AmapString `json:"city"`      // 城市名
+	Adcode    AmapString `json:"adcode"`    // 城市的区域编码
+	Rectangle AmapString `json:"rectangle"` // 城市范围，"左下经度,左下纬度;右上经度,右上纬度"
+}
+
+// AmapGeocode 高德地理编码结果
+type AmapGeocode struct {
+	FormattedAddress AmapString `json:"formatted_address"` // 结构化地址，例如：上海市浦东新区张江镇
+	Country          AmapString `json:"country"`           // 国家
+	Province         AmapString `json:"province"`          // 省份名
+	City             AmapString `json:"city"`              // 城市名，直辖市为空
+	District         AmapString `json:"district"`          // 区县名
+	Adcode           AmapString `json:"adcode"`            // 区域编码
+	Location         AmapString `json:"location"`          // 坐标，"经度,纬度"
+	Level            AmapString `json:"level"`             // 匹配级别，例如：区县、乡镇、兴趣点
+}
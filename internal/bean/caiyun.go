@@ -0,0 +1,25 @@
+package bean
+
+// CaiyunMinutelyResponse 彩云天气分钟级降水响应
+type CaiyunMinutelyResponse struct {
+	Status     string       `json:"status"`      // 状态，ok表示成功
+	Error      string       `json:"error"`       // 失败时的错误信息
+	ServerTime int64        `json:"server_time"` // 服务器时间，Unix时间戳
+	Location   []float64    `json:"location"`    // [纬度, 经度]
+	Result     CaiyunResult `json:"result"`
+}
+
+// CaiyunResult 彩云天气响应结果
+type CaiyunResult struct {
+	Minutely         CaiyunMinutely `json:"minutely"`
+	ForecastKeypoint string         `json:"forecast_keypoint"` // 预报要点
+}
+
+// CaiyunMinutely 彩云天气分钟级降水
+type CaiyunMinutely struct {
+	Status          string    `json:"status"`
+	Datasource      string    `json:"datasource"`       // 数据来源，例如：radar
+	Precipitation2h []float64 `json:"precipitation_2h"` // 未来120分钟逐分钟降水强度，单位：毫米/小时
+	Probability     []float64 `json:"probability"`      // 每30分钟的降水概率，0~1
+	Description     string    `json:"description"`      // 文字描述
+}
@@ -0,0 +1,27 @@
+package bean
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownCRS 坐标系名称无效
+var ErrUnknownCRS = errors.New("未知的坐标系")
+
+// 坐标系
+const (
+	CRSWGS84 = "wgs84" // GPS使用的坐标系，Open-Meteo、美国国家气象局等国外接口使用
+	CRSGCJ02 = "gcj02" // 国测局坐标系，高德地图、和风天气在中国大陆使用
+	CRSBD09  = "bd09"  // 百度地图坐标系
+)
+
+// ParseCRS 校验坐标系名称，只接受与工具Schema一致的wgs84、gcj02、bd09，空字符串视为WGS-84
+func ParseCRS(name string) (string, error) {
+	switch name {
+	case "":
+		return CRSWGS84, nil
+	case CRSWGS84, CRSGCJ02, CRSBD09:
+		return name, nil
+	}
+	return "", fmt.Errorf("%w: %s，可选: %s、%s、%s", ErrUnknownCRS, name, CRSWGS84, CRSGCJ02, CRSBD09)
+}
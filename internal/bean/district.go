@@ -0,0 +1,75 @@
+package bean
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 行政区划级别
+const (
+	DistrictLevelProvince = "province"
+	DistrictLevelCity     = "city"
+	DistrictLevelDistrict = "district"
+)
+
+// District 行政区划
+type District struct {
+	Adcode   string `json:"adcode"`             // 高德区域编码
+	Citycode string `json:"citycode,omitempty"` // 高德城市编码（电话区号），例如：010，省级区划通常为空
+	Name     string `json:"name"`               // 名称，例如：朝阳区
+	FullName string `json:"full_name"`          // 带上级区划的完整名称，例如：北京市朝阳区
+	Level    string `json:"level"`              // 级别：province、city、district
+	Pinyin   string `json:"pinyin,omitempty"`   // 不含行政区划后缀的拼音，例如：chaoyang
+}
+
+// LocationSearchRequest 行政区划搜索请求参数
+type LocationSearchRequest struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// LocationSearchResponse 行政区划搜索结果
+type LocationSearchResponse struct {
+	Query   string     `json:"query"`   // 查询内容
+	Results []District `json:"results"` // 匹配的行政区划，按匹配程度排序
+}
+
+// ResolvedLocation 通过地理编码解析出的地点
+type ResolvedLocation struct {
+	Query            string  `json:"query"`             // 原始输入
+	Adcode           string  `json:"adcode"`            // 区域编码
+	FormattedAddress string  `json:"formatted_address"` // 结构化地址
+	Latitude         float64 `json:"latitude"`          // 纬度
+	Longitude        float64 `json:"longitude"`         // 经度
+	Level            string  `json:"level,omitempty"`   // 匹配级别
+}
+
+// AdministrativeArea 逆地理编码得到的坐标所在行政区划，直辖市的City为空
+type AdministrativeArea struct {
+	Country          string  `json:"country"`                     // 国家
+	Province         string  `json:"province"`                    // 省级区划，例如：北京市
+	City             string  `json:"city,omitempty"`              // 地级区划，例如：杭州市
+	District         string  `json:"district,omitempty"`          // 区县，例如：朝阳区
+	Township         string  `json:"township,omitempty"`          // 乡镇或街道
+	Adcode           string  `json:"adcode"`                      // 区县的区域编码
+	Citycode         string  `json:"citycode,omitempty"`          // 城市编码
+	FormattedAddress string  `json:"formatted_address,omitempty"` // 结构化地址
+	Latitude         float64 `json:"latitude"`                    // 纬度
+	Longitude        float64 `json:"longitude"`                   // 经度
+	CRS              string  `json:"crs"`                         // 经纬度的坐标系，按坐标查询时与请求一致，按IP定位时为gcj02
+}
+
+// AmbiguousLocationError 地点对应多个行政区划时返回的错误
+type AmbiguousLocationError struct {
+	Location   string     `json:"location"`   // 请求的地点
+	Candidates []District `json:"candidates"` // 候选区划
+}
+
+// Error 返回错误信息，包含所有候选区划
+func (e *AmbiguousLocationError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, d := range e.Candidates {
+		names[i] = fmt.Sprintf("%s (%s)", d.FullName, d.Adcode)
+	}
+	return fmt.Sprintf("地点\"%s\"对应多个行政区划: %s，请使用区域编码或带上级区划的名称", e.Location, strings.Join(names, "、"))
+}
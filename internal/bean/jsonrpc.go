@@ -0,0 +1,115 @@
+package bean
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// JSONRPCVersion JSON-RPC协议版本
+const JSONRPCVersion = "2.0"
+
+// JSON-RPC 标准错误码
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+)
+
+// JSONRPCMessage JSON-RPC消息，可以是请求、通知或响应
+type JSONRPCMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// JSONRPCError JSON-RPC错误对象
+type JSONRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error 实现error接口
+func (e *JSONRPCError) Error() string {
+	return e.Message
+}
+
+// NewJSONRPCError 创建新的JSON-RPC错误
+func NewJSONRPCError(code int, message string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// IsRequest 是否为请求（带ID的方法调用）
+func (m *JSONRPCMessage) IsRequest() bool {
+	return m.Method != "" && m.hasID()
+}
+
+// IsNotification 是否为通知（不带ID的方法调用）
+func (m *JSONRPCMessage) IsNotification() bool {
+	return m.Method != "" && !m.hasID()
+}
+
+// IsResponse 是否为响应
+func (m *JSONRPCMessage) IsResponse() bool {
+	return m.Method == "" && m.hasID() && (m.Result != nil || m.Error != nil)
+}
+
+// hasID 是否携带非空ID
+func (m *JSONRPCMessage) hasID() bool {
+	return len(m.ID) > 0 && !bytes.Equal(m.ID, []byte("null"))
+}
+
+// NewJSONRPCRequest 创建新的JSON-RPC请求
+func NewJSONRPCRequest(id json.RawMessage, method string, params interface{}) (*JSONRPCMessage, error) {
+	msg := &JSONRPCMessage{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Method:  method,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		msg.Params = data
+	}
+	return msg, nil
+}
+
+// NewJSONRPCNotification 创建新的JSON-RPC通知
+func NewJSONRPCNotification(method string, params interface{}) (*JSONRPCMessage, error) {
+	return NewJSONRPCRequest(nil, method, params)
+}
+
+// NewJSONRPCResponse 创建新的JSON-RPC成功响应
+func NewJSONRPCResponse(id json.RawMessage, result interface{}) *JSONRPCMessage {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return NewJSONRPCErrorResponse(id, NewJSONRPCError(JSONRPCInternalError, "结果序列化失败: "+err.Error()))
+	}
+	return &JSONRPCMessage{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Result:  data,
+	}
+}
+
+// NewJSONRPCErrorResponse 创建新的JSON-RPC错误响应
+func NewJSONRPCErrorResponse(id json.RawMessage, rpcErr *JSONRPCError) *JSONRPCMessage {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &JSONRPCMessage{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Error:   rpcErr,
+	}
+}
@@ -1,15 +1,11 @@
 package bean
 
+import "encoding/json"
+
 // MCPRequest Claude MCP请求结构
 type MCPRequest struct {
-	Name       string                 `json:"name"`
-	Parameters map[string]interface{} `json:"parameters"`
-}
-
-// MCPResponse Claude MCP响应结构
-type MCPResponse struct {
-	Content interface{} `json:"content"`
-	Type    string      `json:"type"`
+	Name       string          `json:"name"`
+	Parameters json.RawMessage `json:"parameters"`
 }
 
 // MCPErrorResponse Claude MCP错误响应结构
@@ -20,15 +16,10 @@ type MCPErrorResponse struct {
 
 // WeatherMCPRequest 天气MCP请求参数
 type WeatherMCPRequest struct {
-	Location string `json:"location"`
-}
-
-// NewMCPResponse 创建新的MCP响应
-func NewMCPResponse(content interface{}) MCPResponse {
-	return MCPResponse{
-		Content: content,
-		Type:    "application/json",
-	}
+	Location  string   `json:"location,omitempty" description:"城市名称、详细地址或高德区域编码，例如：北京、110000、北京市朝阳区 (110105)、上海浦东新区张江。提供经纬度时可以省略，服务启用IP定位时两者都省略则按调用方IP所在城市查询" jsonschema:"minLength=1"`
+	Latitude  *float64 `json:"latitude,omitempty" description:"纬度，与longitude一起使用时按坐标查询，例如：39.9042" jsonschema:"minimum=-90,maximum=90"`
+	Longitude *float64 `json:"longitude,omitempty" description:"经度，与latitude一起使用时按坐标查询，例如：116.4074" jsonschema:"minimum=-180,maximum=180"`
+	CRS       string   `json:"crs,omitempty" description:"经纬度的坐标系：wgs84（GPS，默认）、gcj02（高德、腾讯地图）、bd09（百度地图）" jsonschema:"enum=wgs84|gcj02|bd09"`
 }
 
 // NewMCPErrorResponse 创建新的MCP错误响应
@@ -38,3 +29,305 @@ func NewMCPErrorResponse(err string) MCPErrorResponse {
 		Type:  "error",
 	}
 }
+
+// MCP 协议版本
+const (
+	MCPProtocolVersion         = "2025-06-18"
+	MCPProtocolVersion20250326 = "2025-03-26"
+	MCPProtocolVersion20241105 = "2024-11-05"
+)
+
+// MCPResourceNotFound 资源不存在的错误码
+const MCPResourceNotFound = -32002
+
+// MCPSupportedProtocolVersions 支持的MCP协议版本，按从新到旧排列
+var MCPSupportedProtocolVersions = []string{
+	MCPProtocolVersion,
+	MCPProtocolVersion20250326,
+	MCPProtocolVersion20241105,
+}
+
+// MCPImplementation MCP实现信息
+type MCPImplementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// MCPInitializeParams initialize请求参数
+type MCPInitializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ClientInfo      MCPImplementation      `json:"clientInfo"`
+}
+
+// MCPInitializeResult initialize响应结果
+type MCPInitializeResult struct {
+	ProtocolVersion string                `json:"protocolVersion"`
+	Capabilities    MCPServerCapabilities `json:"capabilities"`
+	ServerInfo      MCPImplementation     `json:"serverInfo"`
+	Instructions    string                `json:"instructions,omitempty"`
+}
+
+// MCPServerCapabilities 服务端能力声明
+type MCPServerCapabilities struct {
+	Tools       *MCPToolsCapability       `json:"tools,omitempty"`
+	Resources   *MCPResourcesCapability   `json:"resources,omitempty"`
+	Prompts     *MCPPromptsCapability     `json:"prompts,omitempty"`
+	Completions *MCPCompletionsCapability `json:"completions,omitempty"`
+	Logging     *MCPLoggingCapability     `json:"logging,omitempty"`
+}
+
+// MCPToolsCapability 工具能力
+type MCPToolsCapability struct {
+	ListChanged bool `json:"listChanged"`
+}
+
+// MCPResourcesCapability 资源能力
+type MCPResourcesCapability struct {
+	Subscribe   bool `json:"subscribe"`
+	ListChanged bool `json:"listChanged"`
+}
+
+// MCPPromptsCapability 提示词能力
+type MCPPromptsCapability struct {
+	ListChanged bool `json:"listChanged"`
+}
+
+// MCPCompletionsCapability 参数补全能力
+type MCPCompletionsCapability struct{}
+
+// MCPLoggingCapability 日志能力
+type MCPLoggingCapability struct{}
+
+// MCPTool MCP工具定义
+type MCPTool struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	InputSchema  interface{} `json:"inputSchema"`
+	OutputSchema interface{} `json:"outputSchema,omitempty"`
+}
+
+// MCPListToolsResult tools/list响应结果
+type MCPListToolsResult struct {
+	Tools []MCPTool `json:"tools"`
+}
+
+// MCPCallToolParams tools/call请求参数
+type MCPCallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// MCPContent MCP内容块
+type MCPContent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// MCPCallToolResult tools/call响应结果
+type MCPCallToolResult struct {
+	Content           []MCPContent `json:"content"`
+	StructuredContent interface{}  `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// NewMCPTextContent 创建文本内容块
+func NewMCPTextContent(text string) MCPContent {
+	return MCPContent{
+		Type: "text",
+		Text: text,
+	}
+}
+
+// NewMCPToolResult 创建工具调用结果，同时包含供模型阅读的文本和供程序使用的结构化数据
+func NewMCPToolResult(text string, structured interface{}) MCPCallToolResult {
+	return MCPCallToolResult{
+		Content:           []MCPContent{NewMCPTextContent(text)},
+		StructuredContent: structured,
+	}
+}
+
+// NewMCPToolErrorResult 创建表示工具执行失败的结果
+func NewMCPToolErrorResult(message string) MCPCallToolResult {
+	return MCPCallToolResult{
+		Content: []MCPContent{NewMCPTextContent(message)},
+		IsError: true,
+	}
+}
+
+// MCPResource MCP资源定义
+type MCPResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// MCPResourceTemplate MCP资源模板定义
+type MCPResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// MCPListResourcesResult resources/list响应结果
+type MCPListResourcesResult struct {
+	Resources []MCPResource `json:"resources"`
+}
+
+// MCPListResourceTemplatesResult resources/templates/list响应结果
+type MCPListResourceTemplatesResult struct {
+	ResourceTemplates []MCPResourceTemplate `json:"resourceTemplates"`
+}
+
+// MCPResourceParams resources/read、resources/subscribe等请求参数
+type MCPResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// MCPResourceContents 资源内容
+type MCPResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// MCPReadResourceResult resources/read响应结果
+type MCPReadResourceResult struct {
+	Contents []MCPResourceContents `json:"contents"`
+}
+
+// MCPPrompt MCP提示词定义
+type MCPPrompt struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Arguments   []MCPPromptArgument `json:"arguments,omitempty"`
+}
+
+// MCPPromptArgument 提示词参数
+type MCPPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// MCPListPromptsResult prompts/list响应结果
+type MCPListPromptsResult struct {
+	Prompts []MCPPrompt `json:"prompts"`
+}
+
+// MCPGetPromptParams prompts/get请求参数
+type MCPGetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// MCPPromptMessage 提示词消息
+type MCPPromptMessage struct {
+	Role    string     `json:"role"`
+	Content MCPContent `json:"content"`
+}
+
+// MCPGetPromptResult prompts/get响应结果
+type MCPGetPromptResult struct {
+	Description string             `json:"description,omitempty"`
+	Messages    []MCPPromptMessage `json:"messages"`
+}
+
+// MCPCompleteReference 补全请求引用的提示词、资源模板或工具
+type MCPCompleteReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// MCPCompleteArgument 需要补全的参数
+type MCPCompleteArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// MCPCompleteParams completion/complete请求参数
+type MCPCompleteParams struct {
+	Ref      MCPCompleteReference `json:"ref"`
+	Argument MCPCompleteArgument  `json:"argument"`
+}
+
+// MCPCompletion 补全结果
+type MCPCompletion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// MCPCompleteResult completion/complete响应结果
+type MCPCompleteResult struct {
+	Completion MCPCompletion `json:"completion"`
+}
+
+// MCPRequestMeta 请求参数中的_meta字段
+type MCPRequestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+}
+
+// MCPRequestParams 只解析请求参数中的_meta字段
+type MCPRequestParams struct {
+	Meta *MCPRequestMeta `json:"_meta,omitempty"`
+}
+
+// MCPProgressParams notifications/progress通知参数
+type MCPProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// MCPCancelledParams notifications/cancelled通知参数
+type MCPCancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// MCPSetLevelParams logging/setLevel请求参数
+type MCPSetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// MCPLoggingMessageParams notifications/message通知参数
+type MCPLoggingMessageParams struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+// Elicitation 的用户操作
+const (
+	MCPElicitActionAccept  = "accept"
+	MCPElicitActionDecline = "decline"
+	MCPElicitActionCancel  = "cancel"
+)
+
+// MCPElicitParams elicitation/create请求参数
+type MCPElicitParams struct {
+	Message         string      `json:"message"`
+	RequestedSchema interface{} `json:"requestedSchema"`
+}
+
+// MCPElicitResult elicitation/create响应结果
+type MCPElicitResult struct {
+	Action  string                 `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// SearchLocationMCPRequest 行政区划搜索MCP请求参数
+type SearchLocationMCPRequest struct {
+	Query string `json:"query" description:"名称、拼音、拼音首字母、区域编码或城市编码，允许少量错别字，例如：朝阳、chaoyang、cy、010" jsonschema:"minLength=1"`
+	Limit int    `json:"limit,omitempty" description:"最多返回的结果数，默认10" jsonschema:"minimum=1,maximum=50"`
+}
+
+// WeatherBatchMCPRequest 批量天气MCP请求参数
+type WeatherBatchMCPRequest struct {
+	Locations []string `json:"locations" description:"城市名称或高德区域编码列表" jsonschema:"minItems=1,maxItems=20"`
+}
@@ -0,0 +1,50 @@
+package bean
+
+import "errors"
+
+// ErrNowcastFailed 短时降水上游接口请求失败或返回错误
+var ErrNowcastFailed = errors.New("获取短时降水失败")
+
+// 降水强度等级，与HourlyForecast.PrecipitationIntensity的取值一致
+const (
+	PrecipitationNone     = "None"
+	PrecipitationLight    = "Light"
+	PrecipitationModerate = "Moderate"
+	PrecipitationHeavy    = "Heavy"
+	PrecipitationStorm    = "Storm"
+)
+
+// NowcastRequest 短时降水预报请求参数
+type NowcastRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"required,min=-180,max=180"`
+	CRS       string   `json:"crs"` // 经纬度的坐标系：wgs84（默认）、gcj02、bd09
+}
+
+// RainNowcastMCPRequest 短时降水预报MCP请求参数
+type RainNowcastMCPRequest struct {
+	Latitude  float64 `json:"latitude" description:"纬度，例如：39.9042" jsonschema:"minimum=-90,maximum=90"`
+	Longitude float64 `json:"longitude" description:"经度，例如：116.4074" jsonschema:"minimum=-180,maximum=180"`
+	CRS       string  `json:"crs,omitempty" description:"经纬度的坐标系：wgs84（GPS，默认）、gcj02（高德、腾讯地图）、bd09（百度地图）" jsonschema:"enum=wgs84|gcj02|bd09"`
+}
+
+// NowcastMinute 逐分钟降水
+type NowcastMinute struct {
+	Minute    int     `json:"minute"`    // 距现在的分钟数，从0开始
+	Intensity float64 `json:"intensity"` // 降水强度，单位：毫米/小时
+	Level     string  `json:"level"`     // 强度等级：None、Light、Moderate、Heavy、Storm
+}
+
+// NowcastResponse 未来2小时逐分钟降水预报
+type NowcastResponse struct {
+	Latitude       float64         `json:"latitude"`
+	Longitude      float64         `json:"longitude"`
+	Description    string          `json:"description"`              // 文字描述，例如：未来两小时不会下雨
+	RainInNextHour bool            `json:"rain_in_next_hour"`        // 未来一小时内是否有降水
+	RainStartsIn   *int            `json:"rain_starts_in,omitempty"` // 多少分钟后开始降水，正在降水时为0，两小时内无降水时省略
+	MaxIntensity   float64         `json:"max_intensity"`            // 两小时内的最大降水强度，单位：毫米/小时
+	Probability    []int           `json:"probability"`              // 每30分钟的降水概率
+	Minutely       []NowcastMinute `json:"minutely"`                 // 未来120分钟逐分钟降水
+	ForecastTime   string          `json:"forecast_time,omitempty"`
+	Provider       string          `json:"provider,omitempty"`
+}
@@ -0,0 +1,70 @@
+package bean
+
+// NWSPointsResponse 美国国家气象局坐标点查询响应
+type NWSPointsResponse struct {
+	Properties NWSPointProperties `json:"properties"`
+}
+
+// NWSPointProperties 坐标点对应的预报网格
+type NWSPointProperties struct {
+	GridID           string              `json:"gridId"`         // 预报办公室，例如：OKX
+	GridX            int                 `json:"gridX"`          // 网格横坐标
+	GridY            int                 `json:"gridY"`          // 网格纵坐标
+	Forecast         string              `json:"forecast"`       // 网格逐12小时预报地址
+	ForecastHourly   string              `json:"forecastHourly"` // 网格逐小时预报地址
+	TimeZone         string              `json:"timeZone"`       // 时区，例如：America/New_York
+	RelativeLocation NWSRelativeLocation `json:"relativeLocation"`
+}
+
+// NWSRelativeLocation 坐标点附近的城市
+type NWSRelativeLocation struct {
+	Properties struct {
+		City  string `json:"city"`
+		State string `json:"state"`
+	} `json:"properties"`
+}
+
+// NWSGridpoint 缓存的预报网格信息
+type NWSGridpoint struct {
+	GridID         string `json:"grid_id"`
+	GridX          int    `json:"grid_x"`
+	GridY          int    `json:"grid_y"`
+	Forecast       string `json:"forecast"`
+	ForecastHourly string `json:"forecast_hourly"`
+	City           string `json:"city"`
+	State          string `json:"state"`
+}
+
+// NWSForecastResponse 网格预报响应，逐12小时预报和逐小时预报结构相同
+type NWSForecastResponse struct {
+	Properties struct {
+		UpdateTime string              `json:"updateTime"` // 预报更新时间
+		Periods    []NWSForecastPeriod `json:"periods"`
+	} `json:"properties"`
+}
+
+// NWSForecastPeriod 预报时段
+type NWSForecastPeriod struct {
+	Number                     int         `json:"number"`
+	Name                       string      `json:"name"`            // 时段名称，例如：Tonight
+	StartTime                  string      `json:"startTime"`       // 开始时间
+	IsDaytime                  bool        `json:"isDaytime"`       // 是否为白天
+	Temperature                float64     `json:"temperature"`     // 温度
+	TemperatureUnit            string      `json:"temperatureUnit"` // 温度单位：F或C
+	ProbabilityOfPrecipitation NWSQuantity `json:"probabilityOfPrecipitation"`
+	RelativeHumidity           NWSQuantity `json:"relativeHumidity"`
+	ShortForecast              string      `json:"shortForecast"` // 简短描述，例如：Chance Rain Showers
+}
+
+// NWSQuantity 带单位的数值，没有数据时Value为null
+type NWSQuantity struct {
+	UnitCode string   `json:"unitCode"`
+	Value    *float64 `json:"value"`
+}
+
+// NWSErrorResponse 美国国家气象局错误响应
+type NWSErrorResponse struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
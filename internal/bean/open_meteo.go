@@ -0,0 +1,59 @@
+package bean
+
+// OpenMeteoForecastResponse Open-Meteo天气预报响应
+type OpenMeteoForecastResponse struct {
+	Latitude  float64          `json:"latitude"`           // 纬度
+	Longitude float64          `json:"longitude"`          // 经度
+	Timezone  string           `json:"timezone"`           // 时区，例如：Asia/Shanghai
+	UTCOffset int              `json:"utc_offset_seconds"` // 当地时间与UTC的偏移，单位：秒
+	Current   OpenMeteoCurrent `json:"current"`            // 当前天气
+	Hourly    OpenMeteoHourly  `json:"hourly"`             // 逐小时预报，各字段按下标对应
+	Daily     OpenMeteoDaily   `json:"daily"`              // 逐日预报，各字段按下标对应
+}
+
+// OpenMeteoCurrent Open-Meteo当前天气
+type OpenMeteoCurrent struct {
+	Time             string  `json:"time"`                 // 当地时间，例如：2024-01-01T08:00
+	Temperature      float64 `json:"temperature_2m"`       // 气温，单位：摄氏度
+	RelativeHumidity int     `json:"relative_humidity_2m"` // 相对湿度
+	Precipitation    float64 `json:"precipitation"`        // 降水量，单位：毫米
+	WeatherCode      int     `json:"weather_code"`         // WMO天气代码
+}
+
+// OpenMeteoHourly Open-Meteo逐小时预报
+type OpenMeteoHourly struct {
+	Time                     []string  `json:"time"`                      // 当地时间
+	Temperature              []float64 `json:"temperature_2m"`            // 气温，单位：摄氏度
+	PrecipitationProbability []int     `json:"precipitation_probability"` // 降水概率
+	WeatherCode              []int     `json:"weather_code"`              // WMO天气代码
+}
+
+// OpenMeteoDaily Open-Meteo逐日预报
+type OpenMeteoDaily struct {
+	Time             []string  `json:"time"`               // 当地日期，例如：2024-01-01
+	WeatherCode      []int     `json:"weather_code"`       // WMO天气代码
+	TemperatureMax   []float64 `json:"temperature_2m_max"` // 最高气温，单位：摄氏度
+	TemperatureMin   []float64 `json:"temperature_2m_min"` // 最低气温，单位：摄氏度
+	PrecipitationSum []float64 `json:"precipitation_sum"`  // 降水量，单位：毫米
+}
+
+// OpenMeteoErrorResponse Open-Meteo错误响应
+type OpenMeteoErrorResponse struct {
+	Error  bool   `json:"error"`
+	Reason string `json:"reason"`
+}
+
+// OpenMeteoGeocodingResponse Open-Meteo地理编码响应
+type OpenMeteoGeocodingResponse struct {
+	Results []OpenMeteoGeocodingResult `json:"results"`
+}
+
+// OpenMeteoGeocodingResult Open-Meteo地理编码结果
+type OpenMeteoGeocodingResult struct {
+	ID        int64   `json:"id"`        // 地点ID
+	Name      string  `json:"name"`      // 地点名称
+	Latitude  float64 `json:"latitude"`  // 纬度
+	Longitude float64 `json:"longitude"` // 经度
+	Country   string  `json:"country"`   // 国家名称
+	Admin1    string  `json:"admin1"`    // 一级行政区名称
+}
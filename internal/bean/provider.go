@@ -0,0 +1,43 @@
+package bean
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrProviderUnavailable 所有天气数据源都不可用
+var ErrProviderUnavailable = errors.New("天气数据源暂不可用")
+
+// ErrLocationRequired 请求没有给出地点，并且无法按客户端IP定位
+var ErrLocationRequired = errors.New("需要location，或者latitude和longitude")
+
+// LocationNotFoundError 天气数据源找不到请求的地点
+type LocationNotFoundError struct {
+	Location string
+}
+
+// Error 返回错误信息
+func (e *LocationNotFoundError) Error() string {
+	return fmt.Sprintf("未找到位置: %s", e.Location)
+}
+
+// 天气数据源的覆盖范围
+const (
+	ProviderCoverageChina  = "china"
+	ProviderCoverageGlobal = "global"
+	ProviderCoverageUS     = "us"
+)
+
+// ProviderCapabilities 天气数据源的能力
+type ProviderCapabilities struct {
+	HourlyData bool   `json:"hourly_data"` // 是否提供真实的逐小时预报，否则逐小时预报由逐日预报推算
+	DailyData  bool   `json:"daily_data"`  // 是否提供逐日预报
+	Coverage   string `json:"coverage"`    // 覆盖范围：china、us、global，组合多个数据源时以逗号分隔，例如china,us
+	Adcodes    bool   `json:"adcodes"`     // 是否能按高德区域编码查询
+}
+
+// ProviderInfo 天气数据源信息
+type ProviderInfo struct {
+	Name         string               `json:"name"`
+	Capabilities ProviderCapabilities `json:"capabilities"`
+}
@@ -0,0 +1,93 @@
+package bean
+
+// QWeatherCityLookupResponse 和风天气城市搜索响应
+type QWeatherCityLookupResponse struct {
+	Code     string             `json:"code"`     // 状态码，200表示成功
+	Location []QWeatherLocation `json:"location"` // 匹配的城市
+}
+
+// QWeatherLocation 和风天气城市信息
+type QWeatherLocation struct {
+	ID      string `json:"id"`      // 和风天气LocationID
+	Name    string `json:"name"`    // 城市名称
+	Adm1    string `json:"adm1"`    // 一级行政区
+	Adm2    string `json:"adm2"`    // 二级行政区
+	Country string `json:"country"` // 国家
+	Lat     string `json:"lat"`     // 纬度
+	Lon     string `json:"lon"`     // 经度
+}
+
+// QWeatherNowResponse 和风天气实时天气响应
+type QWeatherNowResponse struct {
+	Code       string      `json:"code"`
+	UpdateTime string      `json:"updateTime"` // 接口更新时间
+	Now        QWeatherNow `json:"now"`
+}
+
+// QWeatherNow 和风天气实时天气
+type QWeatherNow struct {
+	ObsTime  string `json:"obsTime"`  // 观测时间
+	Temp     string `json:"temp"`     // 温度，单位：摄氏度
+	Icon     string `json:"icon"`     // 天气图标代码
+	Text     string `json:"text"`     // 天气描述
+	Humidity string `json:"humidity"` // 相对湿度
+	Precip   string `json:"precip"`   // 过去1小时降水量，单位：毫米
+}
+
+// QWeatherHourlyResponse 和风天气逐小时预报响应
+type QWeatherHourlyResponse struct {
+	Code       string           `json:"code"`
+	UpdateTime string           `json:"updateTime"`
+	Hourly     []QWeatherHourly `json:"hourly"`
+}
+
+// QWeatherHourly 和风天气逐小时预报
+type QWeatherHourly struct {
+	FxTime string `json:"fxTime"` // 预报时间
+	Temp   string `json:"temp"`   // 温度，单位：摄氏度
+	Icon   string `json:"icon"`   // 天气图标代码
+	Text   string `json:"text"`   // 天气描述
+	Pop    string `json:"pop"`    // 降水概率，可能为空
+	Precip string `json:"precip"` // 降水量，单位：毫米
+}
+
+// QWeatherDailyResponse 和风天气逐日预报响应
+type QWeatherDailyResponse struct {
+	Code       string          `json:"code"`
+	UpdateTime string          `json:"updateTime"`
+	Daily      []QWeatherDaily `json:"daily"`
+}
+
+// QWeatherDaily 和风天气逐日预报
+type QWeatherDaily struct {
+	FxDate    string `json:"fxDate"`    // 预报日期
+	TempMax   string `json:"tempMax"`   // 最高温度
+	TempMin   string `json:"tempMin"`   // 最低温度
+	TextDay   string `json:"textDay"`   // 白天天气描述
+	TextNight string `json:"textNight"` // 夜间天气描述
+	Humidity  string `json:"humidity"`  // 相对湿度
+	Precip    string `json:"precip"`    // 降水量，单位：毫米
+}
+
+// QWeatherWarningResponse 和风天气预警响应
+type QWeatherWarningResponse struct {
+	Code       string            `json:"code"`
+	UpdateTime string            `json:"updateTime"`
+	Warning    []QWeatherWarning `json:"warning"`
+}
+
+// QWeatherWarning 和风天气预警
+type QWeatherWarning struct {
+	ID            string `json:"id"`            // 预警ID
+	Sender        string `json:"sender"`        // 发布单位
+	PubTime       string `json:"pubTime"`       // 发布时间
+	Title         string `json:"title"`         // 预警标题
+	StartTime     string `json:"startTime"`     // 开始时间
+	EndTime       string `json:"endTime"`       // 结束时间
+	Status        string `json:"status"`        // 状态
+	Severity      string `json:"severity"`      // 严重等级
+	SeverityColor string `json:"severityColor"` // 预警颜色
+	Type          string `json:"type"`          // 预警类型
+	TypeName      string `json:"typeName"`      // 预警类型名称
+	Text          string `json:"text"`          // 预警详细内容
+}
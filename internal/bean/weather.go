@@ -1,8 +1,11 @@
 package bean
 
-// WeatherRequest 天气请求参数
+// WeatherRequest 天气请求参数，location与latitude、longitude二选一
 type WeatherRequest struct {
-	Location string `json:"location" binding:"required"`
+	Location  string   `json:"location"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	CRS       string   `json:"crs"` // 经纬度的坐标系：wgs84（默认）、gcj02、bd09
 }
 
 // Temperature 温度信息
@@ -13,30 +16,104 @@ type Temperature struct {
 
 // CurrentConditions 当前天气状况
 type CurrentConditions struct {
-	Temperature      Temperature `json:"temperature"`
-	WeatherText      string      `json:"weather_text"`
-	RelativeHumidity int         `json:"relative_humidity"`
-	Precipitation    bool        `json:"precipitation"`
-	ObservationTime  string      `json:"observation_time"`
+	Temperature      Temperature     `json:"temperature"`
+	WeatherText      string          `json:"weather_text"`
+	RelativeHumidity int             `json:"relative_humidity"`
+	Precipitation    bool            `json:"precipitation"`
+	ObservationTime  string          `json:"observation_time"`
+	Ensemble         *EnsembleSpread `json:"ensemble,omitempty"` // 集合预报时各数据源的分歧
 }
 
 // HourlyForecast 每小时天气预报
 type HourlyForecast struct {
-	RelativeTime             string      `json:"relative_time"`
-	Temperature              Temperature `json:"temperature"`
-	WeatherText              string      `json:"weather_text"`
-	PrecipitationProbability int         `json:"precipitation_probability"`
-	PrecipitationType        string      `json:"precipitation_type"`
-	PrecipitationIntensity   string      `json:"precipitation_intensity"`
+	RelativeTime             string          `json:"relative_time"`
+	DateTime                 string          `json:"date_time,omitempty"` // 时段开始时间，RFC 3339格式，例如：2024-01-01T08:00:00+08:00
+	Temperature              Temperature     `json:"temperature"`
+	WeatherText              string          `json:"weather_text"`
+	PrecipitationProbability int             `json:"precipitation_probability"`
+	PrecipitationType        string          `json:"precipitation_type"`
+	PrecipitationIntensity   string          `json:"precipitation_intensity"`
+	Ensemble                 *EnsembleSpread `json:"ensemble,omitempty"` // 集合预报时各数据源的分歧
+}
+
+// EnsembleSpread 集合预报中参与合并的数据源数量及其分歧
+type EnsembleSpread struct {
+	Members                        int     `json:"members"`                          // 参与合并的数据源数量
+	TemperatureSpread              float64 `json:"temperature_spread"`               // 最高与最低气温之差
+	PrecipitationProbabilitySpread int     `json:"precipitation_probability_spread"` // 最高与最低降水概率之差
+	PrecipitationTypeAgreement     float64 `json:"precipitation_type_agreement"`     // 降水类型与合并结果一致的数据源比例，0~1
+}
+
+// DailyForecast 逐日天气预报
+type DailyForecast struct {
+	Date             string      `json:"date"`               // 日期，例如：2024-01-01
+	TempMax          Temperature `json:"temp_max"`           // 最高气温
+	TempMin          Temperature `json:"temp_min"`           // 最低气温
+	WeatherTextDay   string      `json:"weather_text_day"`   // 白天天气
+	WeatherTextNight string      `json:"weather_text_night"` // 夜间天气
+	Precipitation    float64     `json:"precipitation"`      // 降水量，单位：毫米
+	RelativeHumidity int         `json:"relative_humidity"`  // 相对湿度
+}
+
+// WeatherWarning 官方发布的天气预警
+type WeatherWarning struct {
+	ID        string `json:"id"`                   // 预警ID
+	Sender    string `json:"sender,omitempty"`     // 发布单位
+	PubTime   string `json:"pub_time"`             // 发布时间
+	Title     string `json:"title"`                // 预警标题
+	StartTime string `json:"start_time,omitempty"` // 开始时间
+	EndTime   string `json:"end_time,omitempty"`   // 结束时间
+	Status    string `json:"status,omitempty"`     // 状态：active、update
+	Severity  string `json:"severity"`             // 严重等级，例如：Minor、Moderate、Severe
+	Color     string `json:"color,omitempty"`      // 预警颜色，例如：Blue、Yellow、Orange、Red
+	Type      string `json:"type"`                 // 预警类型
+	TypeName  string `json:"type_name"`            // 预警类型名称，例如：暴雨
+	Text      string `json:"text"`                 // 预警详细内容
 }
 
 // WeatherResponse 天气响应数据
 type WeatherResponse struct {
+	Location          string              `json:"location"`
+	LocationKey       string              `json:"location_key"`
+	Country           string              `json:"country"`
+	CurrentConditions CurrentConditions   `json:"current_conditions"`
+	HourlyForecast    []HourlyForecast    `json:"hourly_forecast"`
+	ForecastTime      string              `json:"forecast_time,omitempty"`
+	DailyForecast     []DailyForecast     `json:"daily_forecast,omitempty"` // 逐日预报，数据源支持时提供
+	Warnings          []WeatherWarning    `json:"warnings,omitempty"`       // 生效中的天气预警，数据源支持时提供
+	Provider          string              `json:"provider,omitempty"`       // 提供数据的天气数据源
+	Sources           []string            `json:"sources,omitempty"`        // 集合预报时实际参与合并的数据源
+	Region            *AdministrativeArea `json:"region,omitempty"`         // 按坐标或客户端IP查询时所在的行政区划
+}
+
+// WeatherBatchItem 批量查询中单个地点的结果
+type WeatherBatchItem struct {
+	Location string           `json:"location"`
+	Weather  *WeatherResponse `json:"weather,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// WeatherBatchResponse 批量天气查询结果
+type WeatherBatchResponse struct {
+	Results []WeatherBatchItem `json:"results"`
+}
+
+// CurrentWeather 实时天气，作为MCP资源内容
+type CurrentWeather struct {
 	Location          string            `json:"location"`
 	LocationKey       string            `json:"location_key"`
 	Country           string            `json:"country"`
 	CurrentConditions CurrentConditions `json:"current_conditions"`
-	HourlyForecast    []HourlyForecast  `json:"hourly_forecast"`
+}
+
+// ForecastWeather 天气预报，作为MCP资源内容
+type ForecastWeather struct {
+	Location       string           `json:"location"`
+	LocationKey    string           `json:"location_key"`
+	Country        string           `json:"country"`
+	ForecastTime   string           `json:"forecast_time,omitempty"`
+	HourlyForecast []HourlyForecast `json:"hourly_forecast"`
+	DailyForecast  []DailyForecast  `json:"daily_forecast,omitempty"`
 }
 
 // AccuWeatherLocationResponse AccuWeather位置响应
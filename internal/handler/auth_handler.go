@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tung/mcp/internal/auth"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// authLogger 鉴权的日志名称
+const authLogger = "auth"
+
+// oauthMetadataPath 受保护资源元数据的路径（RFC 9728）
+const oauthMetadataPath = "/.well-known/oauth-protected-resource"
+
+// authMetadataURLKey gin上下文中受保护资源元数据URL的键
+const authMetadataURLKey = "auth.metadataURL"
+
+// authRouteScopes 访问REST路由需要的权限范围，未列出的路由（如/locations/search）只需有效的访问令牌，
+// MCP请求的权限范围见mcpRequiredScopes
+var authRouteScopes = map[string][]string{
+	"/weather": {auth.ScopeWeatherRead},
+	"/nowcast": {auth.ScopeWeatherRead},
+}
+
+// AuthHandler OAuth资源服务器处理器接口
+type AuthHandler interface {
+	RegisterRoutes(router *gin.Engine)
+	Authenticate() gin.HandlerFunc
+}
+
+// authHandler OAuth资源服务器处理器实现
+type authHandler struct {
+	verifier    auth.Verifier
+	metadata    bean.OAuthProtectedResourceMetadata
+	metadataURL string
+}
+
+// NewAuthHandler 创建新的OAuth资源服务器处理器，resource为本服务的资源标识（MCP端点的完整URL），
+// issuer为授权服务器地址
+func NewAuthHandler(verifier auth.Verifier, resource, issuer string) AuthHandler {
+	metadata := bean.OAuthProtectedResourceMetadata{
+		Resource:               resource,
+		ScopesSupported:        []string{auth.ScopeWeatherRead, auth.ScopeWeatherBatch},
+		BearerMethodsSupported: []string{"header"},
+		ResourceName:           mcpServerName,
+	}
+	if issuer != "" {
+		metadata.AuthorizationServers = []string{issuer}
+	}
+
+	return &authHandler{
+		verifier:    verifier,
+		metadata:    metadata,
+		metadataURL: oauthMetadataURL(resource),
+	}
+}
+
+// RegisterRoutes 注册受保护资源元数据路由，元数据本身不需要鉴权
+func (h *authHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET(oauthMetadataPath, h.handleMetadata)
+	if u, err := url.Parse(h.metadata.Resource); err == nil && strings.Trim(u.Path, "/") != "" {
+		router.GET(oauthMetadataPath+"/"+strings.Trim(u.Path, "/"), h.handleMetadata)
+	}
+}
+
+// handleMetadata 返回受保护资源元数据
+func (h *authHandler) handleMetadata(c *gin.Context) {
+	c.JSON(http.StatusOK, h.metadata)
+}
+
+// Authenticate 校验Bearer访问令牌，通过后将令牌声明放入请求上下文
+func (h *authHandler) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(authMetadataURLKey, h.metadataURL)
+
+		header := c.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			writeAuthChallenge(c, http.StatusUnauthorized, "", "需要访问令牌", nil)
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		claims, err := h.verifier.Verify(ctx, strings.TrimSpace(token))
+		if err != nil {
+			logging.Warningf(ctx, authLogger, "访问令牌校验失败: %v", err)
+			writeAuthChallenge(c, http.StatusUnauthorized, "invalid_token", err.Error(), nil)
+			c.Abort()
+			return
+		}
+
+		if scopes := authRouteScopes[c.FullPath()]; !claims.HasScopes(scopes...) {
+			writeAuthChallenge(c, http.StatusForbidden, "insufficient_scope", "访问令牌缺少所需的权限范围", scopes)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithClaims(ctx, claims))
+		c.Next()
+	}
+}
+
+// checkScopes 校验请求的访问令牌是否包含所需权限范围，不包含时写入403响应。
+// 未启用鉴权时请求上下文中没有令牌声明，总是通过
+func checkScopes(c *gin.Context, scopes []string) bool {
+	claims := auth.ClaimsFromContext(c.Request.Context())
+	if claims == nil || claims.HasScopes(scopes...) {
+		return true
+	}
+	writeAuthChallenge(c, http.StatusForbidden, "insufficient_scope", "访问令牌缺少所需的权限范围", scopes)
+	return false
+}
+
+// writeAuthChallenge 写入带WWW-Authenticate质询的错误响应，code为RFC 6750的错误码，
+// 说明只放在响应体中，避免请求头出现非ASCII字符
+func writeAuthChallenge(c *gin.Context, status int, code, message string, scopes []string) {
+	params := []string{fmt.Sprintf("resource_metadata=%q", c.GetString(authMetadataURLKey))}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code))
+	}
+	if len(scopes) > 0 {
+		params = append(params, fmt.Sprintf("scope=%q", strings.Join(scopes, " ")))
+	}
+	c.Header("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	c.JSON(status, gin.H{"error": message})
+}
+
+// oauthMetadataURL 根据资源标识生成受保护资源元数据的URL，
+// 资源标识带路径时按RFC 9728将元数据路径插入主机名和路径之间
+func oauthMetadataURL(resource string) string {
+	u, err := url.Parse(resource)
+	if err != nil || u.Host == "" {
+		return oauthMetadataPath
+	}
+	path := strings.Trim(u.Path, "/")
+	u.Path = oauthMetadataPath
+	if path != "" {
+		u.Path += "/" + path
+	}
+	u.RawQuery, u.Fragment = "", ""
+	return u.String()
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientIPKey 上下文中客户端IP的键
+type clientIPKey struct{}
+
+// withClientIP 将请求的客户端IP放入上下文。只有来自受信任代理的请求才采用X-Forwarded-For，
+// 受信任代理由gin.Engine.SetTrustedProxies配置
+func withClientIP(ctx context.Context, c *gin.Context) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, c.ClientIP())
+}
+
+// clientIPFromContext 返回上下文中的客户端IP，stdio传输等没有客户端IP时返回空字符串
+func clientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
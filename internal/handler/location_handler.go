@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logic"
+)
+
+// defaultLocationSearchLimit 行政区划搜索默认返回的结果数
+const defaultLocationSearchLimit = 10
+
+// LocationHandler 地点处理器接口
+type LocationHandler interface {
+	SearchLocations(c *gin.Context)
+	RegisterRoutes(router *gin.Engine)
+}
+
+// locationHandler 地点处理器实现
+type locationHandler struct {
+	locationLogic logic.LocationLogic
+}
+
+// NewLocationHandler 创建新的地点处理器
+func NewLocationHandler(locationLogic logic.LocationLogic) LocationHandler {
+	return &locationHandler{
+		locationLogic: locationLogic,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *locationHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/locations/search", h.SearchLocations)
+}
+
+// SearchLocations 在内置行政区划表中搜索地点，不请求上游接口
+func (h *locationHandler) SearchLocations(c *gin.Context) {
+	var req bean.LocationSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "无效的请求参数，需要q，limit为1~50",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, searchLocations(h.locationLogic, req.Query, req.Limit))
+}
+
+// searchLocations 搜索行政区划，limit不大于0时使用默认结果数
+func searchLocations(locationLogic logic.LocationLogic, query string, limit int) *bean.LocationSearchResponse {
+	if limit <= 0 {
+		limit = defaultLocationSearchLimit
+	}
+	results := locationLogic.SearchLocations(query, limit)
+	if results == nil {
+		results = []bean.District{}
+	}
+	return &bean.LocationSearchResponse{
+		Query:   query,
+		Results: results,
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// maxCompletionValues 单次补全最多返回的候选数，MCP规范限制为100
+const maxCompletionValues = 100
+
+// handleComplete 处理completion/complete请求，为地点类参数提供补全
+func (h *mcpHandler) handleComplete(params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPCompleteParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	switch req.Ref.Type {
+	case "ref/prompt":
+		if _, found := h.prompts.get(req.Ref.Name); !found {
+			return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "未知的提示词: "+req.Ref.Name)
+		}
+	case "ref/resource":
+		if !strings.HasPrefix(req.Ref.URI, "weather://adcode/") {
+			return nil, resourceNotFoundError(req.Ref.URI)
+		}
+	case "ref/tool":
+		// 非标准扩展，便于客户端补全工具参数
+		if _, found := h.tools.get(req.Ref.Name); !found {
+			return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "未知的工具: "+req.Ref.Name)
+		}
+	default:
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "不支持的补全引用类型: "+req.Ref.Type)
+	}
+
+	var values []string
+	switch req.Argument.Name {
+	case "location":
+		values = h.completeLocation(req.Argument.Value)
+	case "locations":
+		values = h.completeLocationList(req.Argument.Value)
+	case "adcode":
+		values = h.completeAdcode(req.Argument.Value)
+	}
+
+	return newCompleteResult(values), nil
+}
+
+// completeLocation 补全地点，候选值形如"北京市朝阳区 (110105)"
+func (h *mcpHandler) completeLocation(value string) []string {
+	districts := h.locationLogic.SearchLocations(value, 0)
+	values := make([]string, len(districts))
+	for i, d := range districts {
+		values[i] = formatDistrictValue(d)
+	}
+	return values
+}
+
+// completeLocationList 补全以分隔符分隔的地点列表中的最后一项
+func (h *mcpHandler) completeLocationList(value string) []string {
+	cut := strings.LastIndexAny(value, ",，、;；\n")
+	prefix, last := "", value
+	if cut >= 0 {
+		_, size := firstRune(value[cut:])
+		prefix, last = value[:cut+size], value[cut+size:]
+	}
+
+	values := h.completeLocation(last)
+	for i := range values {
+		values[i] = prefix + values[i]
+	}
+	return values
+}
+
+// completeAdcode 补全区域编码
+func (h *mcpHandler) completeAdcode(value string) []string {
+	districts := h.locationLogic.SearchLocations(value, 0)
+	values := make([]string, len(districts))
+	for i, d := range districts {
+		values[i] = d.Adcode
+	}
+	return values
+}
+
+// newCompleteResult 构建补全结果，超出上限时标记hasMore
+func newCompleteResult(values []string) bean.MCPCompleteResult {
+	if values == nil {
+		values = []string{}
+	}
+	total := len(values)
+	if total > maxCompletionValues {
+		values = values[:maxCompletionValues]
+	}
+	return bean.MCPCompleteResult{
+		Completion: bean.MCPCompletion{
+			Values:  values,
+			Total:   total,
+			HasMore: total > maxCompletionValues,
+		},
+	}
+}
+
+// formatDistrictValue 将行政区划格式化为可直接作为location参数的值
+func formatDistrictValue(d bean.District) string {
+	return fmt.Sprintf("%s (%s)", d.FullName, d.Adcode)
+}
+
+// firstRune 返回字符串的第一个字符及其字节长度
+func firstRune(s string) (rune, int) {
+	for _, r := range s {
+		return r, len(string(r))
+	}
+	return 0, 0
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tung/mcp/internal/logic"
+	"github.com/tung/mcp/internal/service"
+)
+
+// TestCompletedLocationsSplit 补全结果拼成的地点列表能被compare_weather按原样拆分
+func TestCompletedLocationsSplit(t *testing.T) {
+	h := &mcpHandler{locationLogic: logic.NewLocationLogic(service.NewDistrictService())}
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"首项", "海淀", []string{"北京市海淀区 (110108)"}},
+		{"逗号", "北京市朝阳区 (110105),海淀", []string{"北京市朝阳区 (110105)", "北京市海淀区 (110108)"}},
+		{"顿号", "上海、海淀", []string{"上海", "北京市海淀区 (110108)"}},
+		{"换行", "上海\n海淀", []string{"上海", "北京市海淀区 (110108)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := h.completeLocationList(tt.value)
+			if len(values) == 0 {
+				t.Fatalf("completeLocationList(%q) returned no values", tt.value)
+			}
+			if got := splitLocations(values[0]); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitLocations(%q) = %q, want %q", values[0], got, tt.want)
+			}
+		})
+	}
+}
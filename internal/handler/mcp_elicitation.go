@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/jsonschema"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// errElicitationUnavailable 客户端不支持elicitation
+var errElicitationUnavailable = errors.New("客户端不支持elicitation")
+
+// mcpElicitationKey 上下文中可发起elicitation的会话的键
+type mcpElicitationKey struct{}
+
+// withElicitation 将支持elicitation的会话放入上下文
+func withElicitation(ctx context.Context, sess *mcpSession) context.Context {
+	return context.WithValue(ctx, mcpElicitationKey{}, sess)
+}
+
+// getWeather 查询天气，地点对应多个行政区划时请用户选择后继续查询
+func (h *mcpHandler) getWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	response, err := h.weatherLogic.GetHourlyWeather(ctx, location)
+	var ambiguous *bean.AmbiguousLocationError
+	if err == nil || !errors.As(err, &ambiguous) {
+		return response, err
+	}
+
+	adcode, elicitErr := elicitDistrict(ctx, ambiguous)
+	if errors.Is(elicitErr, errElicitationUnavailable) {
+		return nil, err
+	}
+	if elicitErr != nil {
+		logging.Warningf(ctx, mcpLogger, "确认地点%s失败: %v", location, elicitErr)
+		return nil, fmt.Errorf("%w（%v）", err, elicitErr)
+	}
+	return h.weatherLogic.GetHourlyWeather(ctx, adcode)
+}
+
+// elicitDistrict 通过elicitation/create请用户从候选区划中选择一个，返回所选区域编码
+func elicitDistrict(ctx context.Context, ambiguous *bean.AmbiguousLocationError) (string, error) {
+	sess, ok := ctx.Value(mcpElicitationKey{}).(*mcpSession)
+	if !ok {
+		return "", errElicitationUnavailable
+	}
+
+	adcodes := make([]interface{}, len(ambiguous.Candidates))
+	names := make([]string, len(ambiguous.Candidates))
+	for i, d := range ambiguous.Candidates {
+		adcodes[i] = d.Adcode
+		names[i] = d.FullName
+	}
+
+	result, err := sess.request(ctx, "elicitation/create", bean.MCPElicitParams{
+		Message: fmt.Sprintf("\"%s\"对应多个地点，请选择要查询的地点", ambiguous.Location),
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"adcode": {
+					Type:        "string",
+					Title:       "地点",
+					Description: "要查询天气的行政区划",
+					Enum:        adcodes,
+					EnumNames:   names,
+				},
+			},
+			Required: []string{"adcode"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var elicit bean.MCPElicitResult
+	if err := json.Unmarshal(result, &elicit); err != nil {
+		return "", fmt.Errorf("解析elicitation响应失败: %w", err)
+	}
+	if elicit.Action != bean.MCPElicitActionAccept {
+		return "", fmt.Errorf("用户未选择地点")
+	}
+
+	adcode, _ := elicit.Content["adcode"].(string)
+	for _, d := range ambiguous.Candidates {
+		if d.Adcode == adcode {
+			return adcode, nil
+		}
+	}
+	return "", fmt.Errorf("所选地点不在候选列表中: %s", adcode)
+}
@@ -1,81 +1,427 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/auth"
 	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/jsonschema"
+	"github.com/tung/mcp/internal/logging"
 	"github.com/tung/mcp/internal/logic"
 )
 
+// MCP服务端信息
+const (
+	mcpServerName    = "gaode-mcp-weather"
+	mcpServerVersion = "1.0.0"
+)
+
+// mcpLogger MCP处理器的日志名称
+const mcpLogger = "mcp"
+
 // MCPHandler MCP处理器接口
 type MCPHandler interface {
 	HandleMCPRequest(c *gin.Context)
 	RegisterRoutes(router *gin.Engine)
+	ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error
+	Close()
 }
 
 // mcpHandler MCP处理器实现
 type mcpHandler struct {
-	weatherLogic logic.WeatherLogic
+	weatherLogic  logic.WeatherLogic
+	locationLogic logic.LocationLogic
+	nowcastLogic  logic.NowcastLogic
+	sessions      *cache.Cache
+	tools         *mcpToolRegistry
+	resources     *mcpResourceWatcher
+	prompts       *mcpPromptRegistry
+	adcodes       bool // 数据源能否按区域编码查询，否则不提供资源、补全和search_location工具
+}
+
+// NewMCPHandler 创建新的MCP处理器，nowcastLogic为nil时不提供rain_nowcast工具
+func NewMCPHandler(weatherLogic logic.WeatherLogic, locationLogic logic.LocationLogic, nowcastLogic logic.NowcastLogic) MCPHandler {
+	h := &mcpHandler{
+		weatherLogic:  weatherLogic,
+		locationLogic: locationLogic,
+		nowcastLogic:  nowcastLogic,
+		sessions:      newMCPSessionStore(),
+		tools:         newMCPToolRegistry(),
+		prompts:       newMCPPromptRegistry(),
+		adcodes:       weatherLogic.Capabilities().Adcodes,
+	}
+	h.resources = newMCPResourceWatcher(func(ctx context.Context, adcode string) (*bean.WeatherResponse, error) {
+		return h.weatherLogic.GetHourlyWeather(ctx, adcode)
+	})
+	h.registerTools()
+	h.registerPrompts()
+	return h
 }
 
-// NewMCPHandler 创建新的MCP处理器
-func NewMCPHandler(weatherLogic logic.WeatherLogic) MCPHandler {
-	return &mcpHandler{
-		weatherLogic: weatherLogic,
+// registerTools 注册所有MCP工具
+func (h *mcpHandler) registerTools() {
+	registerMCPTool(h.tools, "weather", "查询指定中国城市、区县或经纬度的实时天气和未来12小时天气预报", h.weatherTool, formatWeatherText)
+	if h.adcodes {
+		registerMCPTool(h.tools, "search_location", "在内置的行政区划表中搜索中国的省、市、区县，支持名称、拼音、拼音首字母和错别字容错，返回区域编码，不消耗天气接口配额", h.searchLocationTool, formatLocationSearchText)
 	}
+	registerMCPTool(h.tools, "weather_batch", "批量查询多个地点的天气，逐个地点上报进度，单个地点失败不影响其他地点", h.weatherBatchTool, formatWeatherBatchText)
+	if h.nowcastLogic != nil {
+		registerMCPTool(h.tools, "rain_nowcast", "查询指定经纬度未来2小时逐分钟的降水预报，可回答未来一小时是否会下雨", h.rainNowcastTool, formatNowcastText)
+	}
+}
+
+// mcpToolScopes 启用鉴权时调用各工具需要的权限范围
+var mcpToolScopes = map[string][]string{
+	"weather":       {auth.ScopeWeatherRead},
+	"weather_batch": {auth.ScopeWeatherRead, auth.ScopeWeatherBatch},
+	"rain_nowcast":  {auth.ScopeWeatherRead},
+	// 搜索只查询内置表，不需要权限范围
+	"search_location": nil,
+}
+
+// mcpAdcodeMethods 以区域编码指代地点的方法，数据源不能按区域编码查询时不提供
+var mcpAdcodeMethods = map[string]bool{
+	"resources/list":           true,
+	"resources/templates/list": true,
+	"resources/read":           true,
+	"resources/subscribe":      true,
+	"resources/unsubscribe":    true,
+	"completion/complete":      true,
+}
+
+// mcpRequiredScopes 返回MCP请求需要的权限范围，会消耗高德配额的请求至少需要weather:read
+func mcpRequiredScopes(msg *bean.JSONRPCMessage) []string {
+	switch msg.Method {
+	case "tools/call":
+		var params bean.MCPCallToolParams
+		json.Unmarshal(msg.Params, &params)
+		if scopes, found := mcpToolScopes[params.Name]; found {
+			return scopes
+		}
+		return []string{auth.ScopeWeatherRead}
+	case "resources/read", "resources/subscribe", "prompts/get":
+		return []string{auth.ScopeWeatherRead}
+	}
+	return nil
 }
 
 // RegisterRoutes 注册路由
 func (h *mcpHandler) RegisterRoutes(router *gin.Engine) {
 	router.POST("/mcp", h.HandleMCPRequest)
+	router.GET("/mcp", h.handleMCPStream)
+	router.DELETE("/mcp", h.handleMCPDelete)
+	router.GET(mcpSSEPath, h.handleSSE)
+	router.POST(mcpMessagesPath, h.handleSSEMessage)
+}
+
+// Close 停止资源订阅的后台轮询，服务关闭时调用
+func (h *mcpHandler) Close() {
+	h.resources.close()
 }
 
-// HandleMCPRequest 处理MCP请求
-func (h *mcpHandler) HandleMCPRequest(c *gin.Context) {
+// handleLegacyRequest 处理旧版 {"name": ..., "parameters": ...} 格式的MCP请求
+func (h *mcpHandler) handleLegacyRequest(c *gin.Context, body []byte) {
 	var req bean.MCPRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		c.JSON(http.StatusBadRequest, bean.NewMCPErrorResponse("无效的请求格式"))
 		return
 	}
 
-	// 根据请求的名称分发到不同的处理函数
-	switch req.Name {
-	case "weather":
-		h.handleWeatherRequest(c, req)
-	default:
+	entry, found := h.tools.get(req.Name)
+	if !found {
 		c.JSON(http.StatusBadRequest, bean.NewMCPErrorResponse("未知的请求名称: "+req.Name))
+		return
+	}
+	if !checkScopes(c, mcpToolScopes[req.Name]) {
+		return
 	}
-}
 
-// handleWeatherRequest 处理天气请求
-func (h *mcpHandler) handleWeatherRequest(c *gin.Context, req bean.MCPRequest) {
-	// 解析参数
-	var weatherReq bean.WeatherMCPRequest
-	paramsJSON, err := json.Marshal(req.Parameters)
+	response, err := entry.invoke(withClientIP(c.Request.Context(), c), req.Parameters)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, bean.NewMCPErrorResponse("参数解析失败"))
+		var validationErr *jsonschema.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, bean.NewMCPErrorResponse(validationErr.Error()))
+			return
+		}
+		var ambiguous *bean.AmbiguousLocationError
+		if errors.As(err, &ambiguous) {
+			c.JSON(http.StatusConflict, bean.NewMCPErrorResponse(ambiguous.Error()))
+			return
+		}
+		c.JSON(weatherErrorStatus(err), bean.NewMCPErrorResponse(err.Error()))
 		return
 	}
 
-	if err := json.Unmarshal(paramsJSON, &weatherReq); err != nil {
-		c.JSON(http.StatusBadRequest, bean.NewMCPErrorResponse("参数格式错误"))
+	// 返回与tools/call一致的结果
+	result, err := entry.result(response)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, bean.NewMCPErrorResponse(err.Error()))
 		return
 	}
+	c.JSON(http.StatusOK, result)
+}
 
-	if weatherReq.Location == "" {
-		c.JSON(http.StatusBadRequest, bean.NewMCPErrorResponse("缺少必要参数: location"))
-		return
+// handleMessage 处理一条JSON-RPC消息，请求返回响应，通知、响应以及被客户端取消的请求返回nil
+func (h *mcpHandler) handleMessage(ctx context.Context, sess *mcpSession, msg *bean.JSONRPCMessage) *bean.JSONRPCMessage {
+	if msg.IsNotification() {
+		h.handleNotification(sess, msg)
+		return nil
+	}
+	if msg.IsResponse() {
+		sess.deliverResponse(msg)
+		return nil
+	}
+	if !msg.IsRequest() {
+		return nil
+	}
+
+	if msg.Method != "initialize" && msg.Method != "ping" && !sess.isInitialized() {
+		return bean.NewJSONRPCErrorResponse(msg.ID, bean.NewJSONRPCError(bean.JSONRPCInvalidRequest, "会话尚未初始化"))
+	}
+
+	ctx, untrack := sess.trackRequest(ctx, msg.ID)
+	defer untrack()
+	ctx = logging.WithEmitter(ctx, sess.emitLog)
+	ctx = withProgress(ctx, sess, msg.Params)
+	if sess.supports("elicitation") {
+		ctx = withElicitation(ctx, sess)
+	}
+
+	response := h.dispatch(ctx, sess, msg)
+	// 客户端已取消的请求不再返回响应
+	if errors.Is(context.Cause(ctx), errRequestCancelled) {
+		return nil
+	}
+	return response
+}
+
+// dispatch 按方法分发请求
+func (h *mcpHandler) dispatch(ctx context.Context, sess *mcpSession, msg *bean.JSONRPCMessage) *bean.JSONRPCMessage {
+
+	var (
+		result interface{}
+		rpcErr *bean.JSONRPCError
+	)
+	if !h.adcodes && mcpAdcodeMethods[msg.Method] {
+		return bean.NewJSONRPCErrorResponse(msg.ID, bean.NewJSONRPCError(bean.JSONRPCMethodNotFound, "未知的方法: "+msg.Method))
+	}
+
+	switch msg.Method {
+	case "initialize":
+		result, rpcErr = h.handleInitialize(sess, msg.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = h.handleToolsList()
+	case "tools/call":
+		result, rpcErr = h.handleToolsCall(ctx, msg.Params)
+	case "resources/list":
+		result = h.handleResourcesList()
+	case "resources/templates/list":
+		result = h.handleResourceTemplatesList()
+	case "resources/read":
+		result, rpcErr = h.handleResourcesRead(ctx, msg.Params)
+	case "resources/subscribe":
+		result, rpcErr = h.handleResourcesSubscribe(sess, msg.Params)
+	case "resources/unsubscribe":
+		result, rpcErr = h.handleResourcesUnsubscribe(sess, msg.Params)
+	case "prompts/list":
+		result = h.handlePromptsList()
+	case "prompts/get":
+		result, rpcErr = h.handlePromptsGet(ctx, msg.Params)
+	case "completion/complete":
+		result, rpcErr = h.handleComplete(msg.Params)
+	case "logging/setLevel":
+		result, rpcErr = h.handleSetLevel(sess, msg.Params)
+	default:
+		rpcErr = bean.NewJSONRPCError(bean.JSONRPCMethodNotFound, "未知的方法: "+msg.Method)
+	}
+
+	if rpcErr != nil {
+		return bean.NewJSONRPCErrorResponse(msg.ID, rpcErr)
+	}
+	return bean.NewJSONRPCResponse(msg.ID, result)
+}
+
+// handleNotification 处理客户端通知
+func (h *mcpHandler) handleNotification(sess *mcpSession, msg *bean.JSONRPCMessage) {
+	switch msg.Method {
+	case "notifications/initialized":
+		// initialize请求已完成协商，请求是否可处理只取决于isInitialized
+	case "notifications/cancelled":
+		var params bean.MCPCancelledParams
+		if json.Unmarshal(msg.Params, &params) == nil && len(params.RequestID) > 0 {
+			sess.cancelRequest(params.RequestID)
+		}
+	}
+}
+
+// handleSetLevel 处理logging/setLevel请求，设置推送给该会话的最低日志级别
+func (h *mcpHandler) handleSetLevel(sess *mcpSession, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPSetLevelParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	level, ok := logging.ParseLevel(req.Level)
+	if !ok {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "未知的日志级别: "+req.Level)
+	}
+	sess.setLogLevel(level)
+	return struct{}{}, nil
+}
+
+// handleInitialize 处理initialize请求，协商协议版本
+func (h *mcpHandler) handleInitialize(sess *mcpSession, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPInitializeParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	version := bean.MCPProtocolVersion
+	for _, v := range bean.MCPSupportedProtocolVersions {
+		if v == req.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+	sess.initialize(version, req.ClientInfo, req.Capabilities)
+
+	capabilities := bean.MCPServerCapabilities{
+		Tools:   &bean.MCPToolsCapability{},
+		Prompts: &bean.MCPPromptsCapability{},
+		Logging: &bean.MCPLoggingCapability{},
+	}
+	if h.adcodes {
+		capabilities.Resources = &bean.MCPResourcesCapability{Subscribe: true}
+		capabilities.Completions = &bean.MCPCompletionsCapability{}
 	}
 
-	// 调用逻辑层获取天气数据
-	response, err := h.weatherLogic.GetHourlyWeather(weatherReq.Location)
+	return bean.MCPInitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    capabilities,
+		ServerInfo: bean.MCPImplementation{
+			Name:    mcpServerName,
+			Version: mcpServerVersion,
+		},
+	}, nil
+}
+
+// handleToolsList 处理tools/list请求
+func (h *mcpHandler) handleToolsList() interface{} {
+	return bean.MCPListToolsResult{
+		Tools: h.tools.list(),
+	}
+}
+
+// handleToolsCall 处理tools/call请求
+func (h *mcpHandler) handleToolsCall(ctx context.Context, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPCallToolParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	entry, found := h.tools.get(req.Name)
+	if !found {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "未知的工具: "+req.Name)
+	}
+
+	output, err := entry.invoke(ctx, req.Arguments)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, bean.NewMCPErrorResponse(err.Error()))
-		return
+		var validationErr *jsonschema.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "参数校验失败: "+validationErr.Error())
+		}
+		// 工具执行错误通过isError返回给模型，而不是协议错误
+		return bean.NewMCPToolErrorResult(err.Error()), nil
 	}
 
-	// 返回MCP格式的响应
-	c.JSON(http.StatusOK, bean.NewMCPResponse(response))
+	result, err := entry.result(output)
+	if err != nil {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInternalError, err.Error())
+	}
+	return result, nil
+}
+
+// weatherTool 天气工具，查询实时天气和逐小时预报
+func (h *mcpHandler) weatherTool(ctx context.Context, req bean.WeatherMCPRequest) (*bean.WeatherResponse, error) {
+	if !coordinatesValid(req.Latitude, req.Longitude) {
+		return nil, fmt.Errorf("latitude和longitude需要同时提供")
+	}
+	switch {
+	case req.Latitude != nil:
+		return h.weatherLogic.GetHourlyWeatherAt(ctx, *req.Latitude, *req.Longitude, req.CRS)
+	case strings.TrimSpace(req.Location) == "":
+		ip := clientIPFromContext(ctx)
+		if ip == "" {
+			return nil, fmt.Errorf("当前传输方式无法获取客户端IP，%w", bean.ErrLocationRequired)
+		}
+		return h.weatherLogic.GetHourlyWeatherByIP(ctx, ip)
+	}
+	return h.getWeather(ctx, req.Location)
+}
+
+// searchLocationTool 地点搜索工具，在内置行政区划表中搜索
+func (h *mcpHandler) searchLocationTool(ctx context.Context, req bean.SearchLocationMCPRequest) (*bean.LocationSearchResponse, error) {
+	return searchLocations(h.locationLogic, req.Query, req.Limit), nil
+}
+
+// rainNowcastTool 短时降水工具，查询未来2小时逐分钟降水
+func (h *mcpHandler) rainNowcastTool(ctx context.Context, req bean.RainNowcastMCPRequest) (*bean.NowcastResponse, error) {
+	return h.nowcastLogic.GetNowcast(ctx, req.Latitude, req.Longitude, req.CRS)
+}
+
+// weatherBatchTool 批量天气工具，每完成一个地点上报一次进度
+func (h *mcpHandler) weatherBatchTool(ctx context.Context, req bean.WeatherBatchMCPRequest) (*bean.WeatherBatchResponse, error) {
+	total := float64(len(req.Locations))
+	results := make([]bean.WeatherBatchItem, 0, len(req.Locations))
+	for i, location := range req.Locations {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("批量查询已中止: %w", err)
+		}
+
+		item := bean.WeatherBatchItem{Location: location}
+		response, err := h.getWeather(ctx, location)
+		if err != nil {
+			logging.Warningf(ctx, mcpLogger, "查询%s的天气失败: %v", location, err)
+			item.Error = err.Error()
+		} else {
+			item.Weather = response
+		}
+		results = append(results, item)
+
+		reportProgress(ctx, float64(i+1), total, fmt.Sprintf("已完成%s", location))
+	}
+	return &bean.WeatherBatchResponse{Results: results}, nil
+}
+
+// unmarshalParams 解析请求参数
+func unmarshalParams(params json.RawMessage, v interface{}) *bean.JSONRPCError {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "参数格式错误: "+err.Error())
+	}
+	return nil
+}
+
+// parseJSONRPCMessage 解析JSON-RPC消息
+func parseJSONRPCMessage(data []byte) (*bean.JSONRPCMessage, *bean.JSONRPCError) {
+	var msg bean.JSONRPCMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCParseError, "JSON解析失败")
+	}
+	if msg.JSONRPC != bean.JSONRPCVersion {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidRequest, "无效的JSON-RPC版本")
+	}
+	return &msg, nil
 }
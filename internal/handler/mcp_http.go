@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/auth"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// Streamable HTTP 传输相关的请求头
+const (
+	mcpSessionHeader         = "Mcp-Session-Id"
+	mcpProtocolVersionHeader = "Mcp-Protocol-Version"
+)
+
+// mcp会话与流的参数
+const (
+	mcpSessionTTL       = 30 * time.Minute
+	mcpKeepAliveSeconds = 25
+	mcpStreamBuffer     = 64
+)
+
+// mcpStreamingMethods 在客户端接受SSE时以事件流返回的方法，
+// 这些方法执行期间可能向客户端推送通知或发起elicitation请求
+var mcpStreamingMethods = map[string]bool{
+	"tools/call":  true,
+	"prompts/get": true,
+}
+
+// mcpStream 会话的独立SSE流，由GET请求建立，用于推送与具体请求无关的消息
+type mcpStream struct {
+	mu sync.Mutex
+	ch chan *bean.JSONRPCMessage
+}
+
+// attach 建立流，同一时间只允许一个连接
+func (s *mcpStream) attach() (chan *bean.JSONRPCMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch != nil {
+		return nil, false
+	}
+	s.ch = make(chan *bean.JSONRPCMessage, mcpStreamBuffer)
+	return s.ch, true
+}
+
+// detach 断开流
+func (s *mcpStream) detach(ch chan *bean.JSONRPCMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch == ch {
+		s.ch = nil
+	}
+}
+
+// send 向流推送消息，流不存在或已满时返回错误
+func (s *mcpStream) send(msg *bean.JSONRPCMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch == nil {
+		return errNoStream
+	}
+	select {
+	case s.ch <- msg:
+		return nil
+	default:
+		return fmt.Errorf("会话消息流已满")
+	}
+}
+
+// mcpHTTPSession Streamable HTTP 会话
+type mcpHTTPSession struct {
+	*mcpSession
+	stream  *mcpStream
+	subject string // 启用鉴权时创建会话的令牌主体，会话只能由同一主体使用
+}
+
+// newMCPSessionStore 创建会话存储，空闲超时的会话会被关闭
+func newMCPSessionStore() *cache.Cache {
+	sessions := cache.New(mcpSessionTTL, time.Minute)
+	sessions.OnEvicted(func(_ string, v interface{}) {
+		if sess, ok := v.(*mcpHTTPSession); ok {
+			sess.close()
+		}
+	})
+	return sessions
+}
+
+// HandleMCPRequest 处理Streamable HTTP的POST请求
+func (h *mcpHandler) HandleMCPRequest(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, bean.NewJSONRPCErrorResponse(nil, bean.NewJSONRPCError(bean.JSONRPCParseError, "读取请求失败")))
+		return
+	}
+
+	// 兼容旧版 {"name": ..., "parameters": ...} 请求格式
+	if isLegacyMCPRequest(body) {
+		h.handleLegacyRequest(c, body)
+		return
+	}
+
+	msg, rpcErr := parseJSONRPCMessage(body)
+	if rpcErr != nil {
+		c.JSON(http.StatusBadRequest, bean.NewJSONRPCErrorResponse(nil, rpcErr))
+		return
+	}
+
+	if !h.checkProtocolVersion(c) {
+		return
+	}
+
+	var sess *mcpHTTPSession
+	if msg.Method == "initialize" {
+		if sess = h.createHTTPSession(c); sess == nil {
+			return
+		}
+		c.Header(mcpSessionHeader, sess.id)
+	} else if sess = h.lookupHTTPSession(c); sess == nil {
+		return
+	}
+
+	if !checkScopes(c, mcpRequiredScopes(msg)) {
+		return
+	}
+
+	// 通知和响应不需要返回内容
+	if !msg.IsRequest() {
+		h.handleMessage(sess.ctx, sess.mcpSession, msg)
+		c.Status(http.StatusAccepted)
+		return
+	}
+
+	// 请求在客户端断开或会话结束时取消
+	ctx, cancel := context.WithCancel(withClientIP(c.Request.Context(), c))
+	defer cancel()
+	stop := context.AfterFunc(sess.ctx, cancel)
+	defer stop()
+
+	if mcpStreamingMethods[msg.Method] && acceptsEventStream(c) {
+		h.respondEventStream(ctx, c, sess, msg)
+		return
+	}
+
+	resp := h.handleMessage(ctx, sess.mcpSession, msg)
+	if resp == nil {
+		// 请求已被客户端取消
+		c.Status(http.StatusNoContent)
+		return
+	}
+	if msg.Method == "initialize" && resp.Error != nil {
+		h.sessions.Delete(sess.id)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondEventStream 以SSE流返回请求结果，处理期间的通知在响应之前推送
+func (h *mcpHandler) respondEventStream(ctx context.Context, c *gin.Context, sess *mcpHTTPSession, msg *bean.JSONRPCMessage) {
+	writeEventStreamHeaders(c)
+
+	var mu sync.Mutex
+	send := func(m *bean.JSONRPCMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return writeSSEMessage(c, m)
+	}
+
+	resp := h.handleMessage(withRequestSender(ctx, send), sess.mcpSession, msg)
+	if resp != nil {
+		send(resp)
+	}
+}
+
+// handleMCPStream 处理GET请求，建立推送服务端消息的独立SSE流
+func (h *mcpHandler) handleMCPStream(c *gin.Context) {
+	if !acceptsEventStream(c) {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "需要接受text/event-stream"})
+		return
+	}
+	if !h.checkProtocolVersion(c) {
+		return
+	}
+	sess := h.lookupHTTPSession(c)
+	if sess == nil {
+		return
+	}
+
+	ch, ok := sess.stream.attach()
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "该会话已存在消息流"})
+		return
+	}
+	defer sess.stream.detach(ch)
+
+	writeEventStreamHeaders(c)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(mcpKeepAliveSeconds * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-ch:
+			if err := writeSSEMessage(c, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// 保持连接并刷新会话的过期时间
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+			h.touchHTTPSession(sess)
+		case <-sess.done():
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// handleMCPDelete 处理DELETE请求，结束会话
+func (h *mcpHandler) handleMCPDelete(c *gin.Context) {
+	sess := h.lookupHTTPSession(c)
+	if sess == nil {
+		return
+	}
+	h.sessions.Delete(sess.id)
+	c.Status(http.StatusNoContent)
+}
+
+// createHTTPSession 创建新的HTTP会话，失败时写入错误响应并返回nil
+func (h *mcpHandler) createHTTPSession(c *gin.Context) *mcpHTTPSession {
+	id, err := newSessionID()
+	if err != nil {
+		logging.Errorf(c.Request.Context(), mcpLogger, "%v", err)
+		c.JSON(http.StatusInternalServerError, bean.NewJSONRPCErrorResponse(nil, bean.NewJSONRPCError(bean.JSONRPCInternalError, err.Error())))
+		return nil
+	}
+
+	stream := &mcpStream{}
+	sess := &mcpHTTPSession{
+		mcpSession: newMCPSession(id, stream.send),
+		stream:     stream,
+		subject:    requestSubject(c),
+	}
+	h.sessions.Set(sess.id, sess, cache.DefaultExpiration)
+	return sess
+}
+
+// lookupHTTPSession 根据请求头查找会话，找不到时写入错误响应并返回nil
+func (h *mcpHandler) lookupHTTPSession(c *gin.Context) *mcpHTTPSession {
+	id := c.GetHeader(mcpSessionHeader)
+	if id == "" {
+		c.JSON(http.StatusBadRequest, bean.NewJSONRPCErrorResponse(nil, bean.NewJSONRPCError(bean.JSONRPCInvalidRequest, "缺少"+mcpSessionHeader+"请求头")))
+		return nil
+	}
+
+	sess, found := h.getHTTPSession(c, id)
+	if !found {
+		c.JSON(http.StatusNotFound, bean.NewJSONRPCErrorResponse(nil, bean.NewJSONRPCError(bean.JSONRPCInvalidRequest, "会话不存在或已结束")))
+		return nil
+	}
+	return sess
+}
+
+// getHTTPSession 按ID查找当前请求主体的会话并刷新其过期时间
+func (h *mcpHandler) getHTTPSession(c *gin.Context, id string) (*mcpHTTPSession, bool) {
+	if id == "" {
+		return nil, false
+	}
+	v, found := h.sessions.Get(id)
+	if !found {
+		return nil, false
+	}
+	sess := v.(*mcpHTTPSession)
+	if sess.subject != requestSubject(c) {
+		return nil, false
+	}
+	h.touchHTTPSession(sess)
+	return sess, true
+}
+
+// requestSubject 返回请求访问令牌的主体，未启用鉴权时为空
+func requestSubject(c *gin.Context) string {
+	if claims := auth.ClaimsFromContext(c.Request.Context()); claims != nil {
+		return claims.Issuer + "|" + claims.Subject
+	}
+	return ""
+}
+
+// touchHTTPSession 刷新会话的过期时间
+func (h *mcpHandler) touchHTTPSession(sess *mcpHTTPSession) {
+	h.sessions.Set(sess.id, sess, cache.DefaultExpiration)
+}
+
+// checkProtocolVersion 校验MCP-Protocol-Version请求头，未携带时视为兼容旧版本
+func (h *mcpHandler) checkProtocolVersion(c *gin.Context) bool {
+	version := c.GetHeader(mcpProtocolVersionHeader)
+	if version == "" {
+		return true
+	}
+	for _, v := range bean.MCPSupportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	c.JSON(http.StatusBadRequest, bean.NewJSONRPCErrorResponse(nil, bean.NewJSONRPCError(bean.JSONRPCInvalidRequest, "不支持的协议版本: "+version)))
+	return false
+}
+
+// acceptsEventStream 客户端是否接受SSE响应
+func acceptsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// writeEventStreamHeaders 写入SSE响应头
+func writeEventStreamHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+}
+
+// writeSSEMessage 以message事件写入一条JSON-RPC消息
+func writeSSEMessage(c *gin.Context, msg *bean.JSONRPCMessage) error {
+	return writeSSEEvent(c, "message", msg)
+}
+
+// writeSSEEvent 写入一条SSE事件并立即刷新
+func writeSSEEvent(c *gin.Context, event string, data interface{}) error {
+	var payload []byte
+	switch v := data.(type) {
+	case string:
+		payload = []byte(v)
+	default:
+		var err error
+		if payload, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// isLegacyMCPRequest 是否为旧版非JSON-RPC请求
+func isLegacyMCPRequest(body []byte) bool {
+	var probe struct {
+		JSONRPC string `json:"jsonrpc"`
+		Name    string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return false
+	}
+	return probe.JSONRPC == "" && probe.Name != ""
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// mcpProgressKey 上下文中进度上报目标的键
+type mcpProgressKey struct{}
+
+// mcpProgress 请求的进度上报目标
+type mcpProgress struct {
+	sess  *mcpSession
+	token json.RawMessage
+}
+
+// withProgress 客户端在请求的_meta中携带progressToken时，将进度上报目标放入上下文
+func withProgress(ctx context.Context, sess *mcpSession, params json.RawMessage) context.Context {
+	var req bean.MCPRequestParams
+	if len(params) == 0 || json.Unmarshal(params, &req) != nil {
+		return ctx
+	}
+	if req.Meta == nil || len(req.Meta.ProgressToken) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, mcpProgressKey{}, &mcpProgress{
+		sess:  sess,
+		token: req.Meta.ProgressToken,
+	})
+}
+
+// reportProgress 推送notifications/progress，客户端未请求进度时不做任何事
+func reportProgress(ctx context.Context, progress, total float64, message string) {
+	p, ok := ctx.Value(mcpProgressKey{}).(*mcpProgress)
+	if !ok {
+		return
+	}
+
+	msg, err := bean.NewJSONRPCNotification("notifications/progress", bean.MCPProgressParams{
+		ProgressToken: p.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+	if err != nil {
+		return
+	}
+	p.sess.notify(ctx, msg)
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// maxCompareLocations 对比天气提示词最多支持的地点数量
+const maxCompareLocations = 5
+
+// mcpPromptFunc 提示词的生成函数，参数已校验必填项
+type mcpPromptFunc func(ctx context.Context, args map[string]string) (*bean.MCPGetPromptResult, error)
+
+// mcpPromptEntry 已注册的提示词
+type mcpPromptEntry struct {
+	prompt bean.MCPPrompt
+	build  mcpPromptFunc
+}
+
+// mcpPromptRegistry MCP提示词注册表
+type mcpPromptRegistry struct {
+	mu      sync.RWMutex
+	prompts map[string]*mcpPromptEntry
+	names   []string
+}
+
+// newMCPPromptRegistry 创建新的提示词注册表
+func newMCPPromptRegistry() *mcpPromptRegistry {
+	return &mcpPromptRegistry{
+		prompts: make(map[string]*mcpPromptEntry),
+	}
+}
+
+// register 注册提示词
+func (r *mcpPromptRegistry) register(prompt bean.MCPPrompt, build mcpPromptFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.prompts[prompt.Name]; !exists {
+		r.names = append(r.names, prompt.Name)
+	}
+	r.prompts[prompt.Name] = &mcpPromptEntry{
+		prompt: prompt,
+		build:  build,
+	}
+}
+
+// list 按注册顺序返回所有提示词定义
+func (r *mcpPromptRegistry) list() []bean.MCPPrompt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	prompts := make([]bean.MCPPrompt, 0, len(r.names))
+	for _, name := range r.names {
+		prompts = append(prompts, r.prompts[name].prompt)
+	}
+	return prompts
+}
+
+// get 按名称查找提示词
+func (r *mcpPromptRegistry) get(name string) (*mcpPromptEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, found := r.prompts[name]
+	return entry, found
+}
+
+// registerPrompts 注册所有MCP提示词
+func (h *mcpHandler) registerPrompts() {
+	h.prompts.register(bean.MCPPrompt{
+		Name:        "daily_briefing",
+		Description: "生成指定地点的每日天气简报",
+		Arguments: []bean.MCPPromptArgument{
+			{Name: "location", Description: "城市名称或高德区域编码", Required: true},
+		},
+	}, h.dailyBriefingPrompt)
+
+	h.prompts.register(bean.MCPPrompt{
+		Name:        "packing_advice",
+		Description: "根据目的地天气给出出行打包建议",
+		Arguments: []bean.MCPPromptArgument{
+			{Name: "location", Description: "目的地城市名称或高德区域编码", Required: true},
+			{Name: "date", Description: "出行日期，例如 2025-03-15", Required: true},
+		},
+	}, h.packingAdvicePrompt)
+
+	h.prompts.register(bean.MCPPrompt{
+		Name:        "compare_weather",
+		Description: "对比多个地点的天气",
+		Arguments: []bean.MCPPromptArgument{
+			{Name: "locations", Description: fmt.Sprintf("以逗号或顿号分隔的地点列表，最多%d个", maxCompareLocations), Required: true},
+		},
+	}, h.compareWeatherPrompt)
+}
+
+// handlePromptsList 处理prompts/list请求
+func (h *mcpHandler) handlePromptsList() interface{} {
+	return bean.MCPListPromptsResult{
+		Prompts: h.prompts.list(),
+	}
+}
+
+// handlePromptsGet 处理prompts/get请求
+func (h *mcpHandler) handlePromptsGet(ctx context.Context, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPGetPromptParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	entry, found := h.prompts.get(req.Name)
+	if !found {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "未知的提示词: "+req.Name)
+	}
+
+	for _, arg := range entry.prompt.Arguments {
+		if arg.Required && strings.TrimSpace(req.Arguments[arg.Name]) == "" {
+			return nil, bean.NewJSONRPCError(bean.JSONRPCInvalidParams, "缺少必要参数: "+arg.Name)
+		}
+	}
+
+	result, err := entry.build(ctx, req.Arguments)
+	if err != nil {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInternalError, err.Error())
+	}
+	return result, nil
+}
+
+// dailyBriefingPrompt 每日天气简报
+func (h *mcpHandler) dailyBriefingPrompt(ctx context.Context, args map[string]string) (*bean.MCPGetPromptResult, error) {
+	response, err := h.getWeather(ctx, args["location"])
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("请根据以下实时天气数据，为%s写一份简洁的今日天气简报，"+
+		"包括当前天气、接下来几小时的变化趋势、是否需要带伞以及穿衣建议。\n\n%s",
+		response.Location, formatWeatherText(response))
+
+	return &bean.MCPGetPromptResult{
+		Description: response.Location + "的每日天气简报",
+		Messages:    []bean.MCPPromptMessage{newUserPromptMessage(text)},
+	}, nil
+}
+
+// packingAdvicePrompt 出行打包建议，逐日预报覆盖出行日期时单独列出当天的预报
+func (h *mcpHandler) packingAdvicePrompt(ctx context.Context, args map[string]string) (*bean.MCPGetPromptResult, error) {
+	date := strings.TrimSpace(args["date"])
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return nil, fmt.Errorf("出行日期格式应为YYYY-MM-DD，例如2025-03-15: %s", date)
+	}
+
+	response, err := h.getWeather(ctx, args["location"])
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := "预报不覆盖出行日期，请说明建议的不确定性。"
+	for _, day := range response.DailyForecast {
+		if day.Date == date {
+			forecast = fmt.Sprintf("出行当天的预报：%s转%s，%.0f~%.0f°%s，降水量 %.1f 毫米。",
+				day.WeatherTextDay, day.WeatherTextNight, day.TempMin.Value, day.TempMax.Value, day.TempMax.Unit, day.Precipitation)
+			break
+		}
+	}
+	text := fmt.Sprintf("我计划在%s前往%s。请根据以下天气数据给出打包建议，包括衣物、雨具和其他需要注意的物品。%s\n\n%s",
+		date, response.Location, forecast, formatWeatherText(response))
+
+	return &bean.MCPGetPromptResult{
+		Description: fmt.Sprintf("%s前往%s的打包建议", date, response.Location),
+		Messages:    []bean.MCPPromptMessage{newUserPromptMessage(text)},
+	}, nil
+}
+
+// compareWeatherPrompt 多地天气对比
+func (h *mcpHandler) compareWeatherPrompt(ctx context.Context, args map[string]string) (*bean.MCPGetPromptResult, error) {
+	locations := splitLocations(args["locations"])
+	if len(locations) < 2 {
+		return nil, fmt.Errorf("至少需要两个地点")
+	}
+	if len(locations) > maxCompareLocations {
+		return nil, fmt.Errorf("最多支持%d个地点", maxCompareLocations)
+	}
+
+	sections := make([]string, 0, len(locations))
+	names := make([]string, 0, len(locations))
+	for _, location := range locations {
+		response, err := h.getWeather(ctx, location)
+		if err != nil {
+			return nil, fmt.Errorf("获取%s的天气失败: %w", location, err)
+		}
+		sections = append(sections, formatWeatherText(response))
+		names = append(names, response.Location)
+	}
+
+	text := fmt.Sprintf("请对比以下地点的天气，说明气温、降水和舒适度方面的主要差异，并指出哪里更适合户外活动。\n\n%s",
+		strings.Join(sections, "\n\n"))
+
+	return &bean.MCPGetPromptResult{
+		Description: strings.Join(names, "、") + "的天气对比",
+		Messages:    []bean.MCPPromptMessage{newUserPromptMessage(text)},
+	}, nil
+}
+
+// newUserPromptMessage 创建用户角色的文本提示消息
+func newUserPromptMessage(text string) bean.MCPPromptMessage {
+	return bean.MCPPromptMessage{
+		Role:    "user",
+		Content: bean.NewMCPTextContent(text),
+	}
+}
+
+// splitLocations 拆分以逗号、顿号、分号或换行分隔的地点列表。空格不作分隔符，
+// 补全给出的"北京市朝阳区 (110105)"这类地点中含有空格
+func splitLocations(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		switch r {
+		case ',', '，', '、', ';', '；', '\n':
+			return true
+		}
+		return false
+	})
+
+	locations := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			locations = append(locations, f)
+		}
+	}
+	return locations
+}
@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// 天气资源的类型
+const (
+	weatherResourceNow      = "now"
+	weatherResourceForecast = "forecast"
+)
+
+// weatherResourcePollInterval 检查订阅资源是否更新的间隔
+const weatherResourcePollInterval = 10 * time.Minute
+
+// weatherResourceURIPattern 天气资源URI格式，例如 weather://adcode/110000/now
+var weatherResourceURIPattern = regexp.MustCompile(`^weather://adcode/(\d{6})/(now|forecast)$`)
+
+// weatherResourceCities resources/list 中列出的城市
+var weatherResourceCities = []struct {
+	adcode string
+	name   string
+}{
+	{"110000", "北京市"},
+	{"310000", "上海市"},
+	{"120000", "天津市"},
+	{"500000", "重庆市"},
+	{"440100", "广州市"},
+	{"440300", "深圳市"},
+	{"330100", "杭州市"},
+	{"320100", "南京市"},
+	{"510100", "成都市"},
+	{"420100", "武汉市"},
+	{"610100", "西安市"},
+}
+
+// weatherResourceURI 生成天气资源URI
+func weatherResourceURI(adcode, kind string) string {
+	return fmt.Sprintf("weather://adcode/%s/%s", adcode, kind)
+}
+
+// parseWeatherResourceURI 解析天气资源URI，返回区域编码和资源类型
+func parseWeatherResourceURI(uri string) (string, string, bool) {
+	matches := weatherResourceURIPattern.FindStringSubmatch(uri)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
+
+// handleResourcesList 处理resources/list请求
+func (h *mcpHandler) handleResourcesList() interface{} {
+	resources := make([]bean.MCPResource, 0, len(weatherResourceCities)*2)
+	for _, city := range weatherResourceCities {
+		resources = append(resources,
+			bean.MCPResource{
+				URI:         weatherResourceURI(city.adcode, weatherResourceNow),
+				Name:        city.name + "实时天气",
+				Description: city.name + "的实时天气状况",
+				MimeType:    "application/json",
+			},
+			bean.MCPResource{
+				URI:         weatherResourceURI(city.adcode, weatherResourceForecast),
+				Name:        city.name + "天气预报",
+				Description: city.name + "未来12小时的天气预报",
+				MimeType:    "application/json",
+			},
+		)
+	}
+	return bean.MCPListResourcesResult{Resources: resources}
+}
+
+// handleResourceTemplatesList 处理resources/templates/list请求
+func (h *mcpHandler) handleResourceTemplatesList() interface{} {
+	return bean.MCPListResourceTemplatesResult{
+		ResourceTemplates: []bean.MCPResourceTemplate{
+			{
+				URITemplate: "weather://adcode/{adcode}/now",
+				Name:        "实时天气",
+				Description: "指定高德区域编码的实时天气状况",
+				MimeType:    "application/json",
+			},
+			{
+				URITemplate: "weather://adcode/{adcode}/forecast",
+				Name:        "天气预报",
+				Description: "指定高德区域编码未来12小时的天气预报",
+				MimeType:    "application/json",
+			},
+		},
+	}
+}
+
+// handleResourcesRead 处理resources/read请求
+func (h *mcpHandler) handleResourcesRead(ctx context.Context, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPResourceParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	adcode, kind, ok := parseWeatherResourceURI(req.URI)
+	if !ok {
+		return nil, resourceNotFoundError(req.URI)
+	}
+
+	response, err := h.weatherLogic.GetHourlyWeather(ctx, adcode)
+	if err != nil {
+		var notFound *bean.LocationNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, resourceNotFoundError(req.URI)
+		}
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInternalError, err.Error())
+	}
+	for _, uri := range h.resources.record(adcode, response) {
+		h.resources.notify(uri)
+	}
+
+	var content interface{}
+	switch kind {
+	case weatherResourceNow:
+		content = bean.CurrentWeather{
+			Location:          response.Location,
+			LocationKey:       response.LocationKey,
+			Country:           response.Country,
+			CurrentConditions: response.CurrentConditions,
+		}
+	default:
+		content = bean.ForecastWeather{
+			Location:       response.Location,
+			LocationKey:    response.LocationKey,
+			Country:        response.Country,
+			ForecastTime:   response.ForecastTime,
+			HourlyForecast: response.HourlyForecast,
+			DailyForecast:  response.DailyForecast,
+		}
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, bean.NewJSONRPCError(bean.JSONRPCInternalError, "结果序列化失败: "+err.Error())
+	}
+
+	return bean.MCPReadResourceResult{
+		Contents: []bean.MCPResourceContents{
+			{
+				URI:      req.URI,
+				MimeType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}, nil
+}
+
+// handleResourcesSubscribe 处理resources/subscribe请求
+func (h *mcpHandler) handleResourcesSubscribe(sess *mcpSession, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPResourceParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+	if _, _, ok := parseWeatherResourceURI(req.URI); !ok {
+		return nil, resourceNotFoundError(req.URI)
+	}
+
+	h.resources.subscribe(sess, req.URI)
+	return struct{}{}, nil
+}
+
+// handleResourcesUnsubscribe 处理resources/unsubscribe请求
+func (h *mcpHandler) handleResourcesUnsubscribe(sess *mcpSession, params json.RawMessage) (interface{}, *bean.JSONRPCError) {
+	var req bean.MCPResourceParams
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	h.resources.unsubscribe(sess, req.URI)
+	return struct{}{}, nil
+}
+
+// resourceNotFoundError 资源不存在错误
+func resourceNotFoundError(uri string) *bean.JSONRPCError {
+	rpcErr := bean.NewJSONRPCError(bean.MCPResourceNotFound, "资源不存在")
+	rpcErr.Data = map[string]string{"uri": uri}
+	return rpcErr
+}
+
+// mcpResourceWatcher 跟踪资源订阅，定期检查高德数据的发布时间，
+// 发布时间变化时向订阅的会话推送notifications/resources/updated
+type mcpResourceWatcher struct {
+	fetch  func(ctx context.Context, adcode string) (*bean.WeatherResponse, error)
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	subscribers map[string]map[*mcpSession]func() bool // 订阅的会话及其会话结束时清理订阅的回调的停止函数
+	reportTimes map[string]string
+}
+
+// newMCPResourceWatcher 创建新的资源订阅跟踪器
+func newMCPResourceWatcher(fetch func(ctx context.Context, adcode string) (*bean.WeatherResponse, error)) *mcpResourceWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &mcpResourceWatcher{
+		fetch:       fetch,
+		ctx:         ctx,
+		cancel:      cancel,
+		subscribers: make(map[string]map[*mcpSession]func() bool),
+		reportTimes: make(map[string]string),
+	}
+}
+
+// subscribe 订阅资源，首次订阅时启动轮询
+func (w *mcpResourceWatcher) subscribe(sess *mcpSession, uri string) {
+	w.once.Do(func() {
+		go w.run()
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subs, found := w.subscribers[uri]
+	if !found {
+		subs = make(map[*mcpSession]func() bool)
+		w.subscribers[uri] = subs
+	}
+	// 重复订阅时替换之前注册的清理回调，避免回调随订阅次数累积
+	if stop, subscribed := subs[sess]; subscribed {
+		stop()
+	}
+
+	// 会话结束时清理其订阅
+	subs[sess] = context.AfterFunc(sess.ctx, func() {
+		w.unsubscribe(sess, uri)
+	})
+}
+
+// unsubscribe 取消订阅
+func (w *mcpResourceWatcher) unsubscribe(sess *mcpSession, uri string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subs := w.subscribers[uri]
+	if stop, subscribed := subs[sess]; subscribed {
+		stop()
+	}
+	delete(subs, sess)
+	if len(subs) == 0 {
+		delete(w.subscribers, uri)
+	}
+}
+
+// record 记录资源的最新发布时间，返回发生变化的资源URI
+func (w *mcpResourceWatcher) record(adcode string, response *bean.WeatherResponse) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var updated []string
+	for kind, reportTime := range map[string]string{
+		weatherResourceNow:      response.CurrentConditions.ObservationTime,
+		weatherResourceForecast: response.ForecastTime,
+	} {
+		uri := weatherResourceURI(adcode, kind)
+		previous := w.reportTimes[uri]
+		w.reportTimes[uri] = reportTime
+		if previous != "" && previous != reportTime {
+			updated = append(updated, uri)
+		}
+	}
+	return updated
+}
+
+// close 停止轮询，正在进行的请求随之取消
+func (w *mcpResourceWatcher) close() {
+	w.cancel()
+}
+
+// run 定期轮询所有已订阅的资源，直到调用close
+func (w *mcpResourceWatcher) run() {
+	ticker := time.NewTicker(weatherResourcePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll 检查一次所有已订阅的资源，同一区域的实时天气和预报只请求一次
+func (w *mcpResourceWatcher) poll() {
+	w.mu.Lock()
+	adcodes := make(map[string]struct{})
+	for uri := range w.subscribers {
+		if adcode, _, ok := parseWeatherResourceURI(uri); ok {
+			adcodes[adcode] = struct{}{}
+		}
+	}
+	w.mu.Unlock()
+
+	for adcode := range adcodes {
+		if w.ctx.Err() != nil {
+			return
+		}
+		response, err := w.fetch(w.ctx, adcode)
+		if err != nil {
+			logging.Warningf(w.ctx, mcpLogger, "检查资源更新失败 %s: %v", adcode, err)
+			continue
+		}
+		for _, uri := range w.record(adcode, response) {
+			w.notify(uri)
+		}
+	}
+}
+
+// notify 向订阅资源的会话推送更新通知
+func (w *mcpResourceWatcher) notify(uri string) {
+	msg, err := bean.NewJSONRPCNotification("notifications/resources/updated", bean.MCPResourceParams{URI: uri})
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	sessions := make([]*mcpSession, 0, len(w.subscribers[uri]))
+	for sess := range w.subscribers[uri] {
+		sessions = append(sessions, sess)
+	}
+	w.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.send(msg)
+	}
+}
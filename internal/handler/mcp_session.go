@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// errNoStream 会话当前没有可用于推送消息的流
+var errNoStream = errors.New("会话没有可用的消息流")
+
+// errRequestCancelled 客户端通过notifications/cancelled取消了请求
+var errRequestCancelled = errors.New("请求已被客户端取消")
+
+// mcpSession MCP会话状态
+type mcpSession struct {
+	id     string
+	send   func(msg *bean.JSONRPCMessage) error
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu                 sync.RWMutex
+	initialized        bool
+	protocolVersion    string
+	clientInfo         bean.MCPImplementation
+	clientCapabilities map[string]interface{}
+	logLevel           logging.Level
+	inflight           map[string]context.CancelCauseFunc
+
+	nextRequestID atomic.Int64
+	pending       map[string]chan *bean.JSONRPCMessage
+}
+
+// newMCPSession 创建新的MCP会话，send用于向客户端推送与具体请求无关的消息
+func newMCPSession(id string, send func(msg *bean.JSONRPCMessage) error) *mcpSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &mcpSession{
+		id:       id,
+		send:     send,
+		ctx:      ctx,
+		cancel:   cancel,
+		logLevel: logging.LevelInfo,
+		inflight: make(map[string]context.CancelCauseFunc),
+		pending:  make(map[string]chan *bean.JSONRPCMessage),
+	}
+}
+
+// newSessionID 生成随机的会话ID，随机数源不可用时返回错误，避免生成可预测的ID
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成会话ID失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// initialize 记录initialize请求协商的结果
+func (s *mcpSession) initialize(version string, clientInfo bean.MCPImplementation, capabilities map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initialized = true
+	s.protocolVersion = version
+	s.clientInfo = clientInfo
+	s.clientCapabilities = capabilities
+}
+
+// isInitialized 会话是否已完成initialize请求
+func (s *mcpSession) isInitialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initialized
+}
+
+// supports 客户端是否在initialize时声明了某项能力
+func (s *mcpSession) supports(capability string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.clientCapabilities[capability]
+	return found
+}
+
+// setLogLevel 设置推送给客户端的最低日志级别
+func (s *mcpSession) setLogLevel(level logging.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logLevel = level
+}
+
+// emitLog 将达到会话日志级别的日志以notifications/message推送给客户端
+func (s *mcpSession) emitLog(ctx context.Context, entry logging.Entry) {
+	s.mu.RLock()
+	level := s.logLevel
+	s.mu.RUnlock()
+	if entry.Level < level {
+		return
+	}
+
+	msg, err := bean.NewJSONRPCNotification("notifications/message", bean.MCPLoggingMessageParams{
+		Level:  entry.Level.String(),
+		Logger: entry.Logger,
+		Data:   entry.Message,
+	})
+	if err != nil {
+		return
+	}
+	s.notify(ctx, msg)
+}
+
+// trackRequest 记录进行中的请求，返回可被notifications/cancelled取消的上下文
+func (s *mcpSession) trackRequest(ctx context.Context, id []byte) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	key := string(bytes.TrimSpace(id))
+
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancelRequest 取消进行中的请求
+func (s *mcpSession) cancelRequest(id []byte) {
+	s.mu.RLock()
+	cancel, found := s.inflight[string(bytes.TrimSpace(id))]
+	s.mu.RUnlock()
+	if found {
+		cancel(errRequestCancelled)
+	}
+}
+
+// request 向客户端发送请求并等待响应
+func (s *mcpSession) request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := json.RawMessage(strconv.Quote("srv-" + strconv.FormatInt(s.nextRequestID.Add(1), 10)))
+	msg, err := bean.NewJSONRPCRequest(id, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *bean.JSONRPCMessage, 1)
+	s.mu.Lock()
+	s.pending[string(id)] = ch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, string(id))
+		s.mu.Unlock()
+	}()
+
+	if err := s.notify(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("客户端返回错误: %s", resp.Error.Message)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// deliverResponse 将客户端的响应交给等待中的请求
+func (s *mcpSession) deliverResponse(msg *bean.JSONRPCMessage) {
+	s.mu.RLock()
+	ch, found := s.pending[string(bytes.TrimSpace(msg.ID))]
+	s.mu.RUnlock()
+	if found {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// close 结束会话，取消会话上所有进行中的请求
+func (s *mcpSession) close() {
+	s.cancel()
+}
+
+// done 会话结束时关闭的通道
+func (s *mcpSession) done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// mcpSenderKey 上下文中请求级消息发送函数的键
+type mcpSenderKey struct{}
+
+// withRequestSender 将当前请求的消息发送函数放入上下文，
+// 处理请求期间产生的通知会优先通过该请求的流返回给客户端
+func withRequestSender(ctx context.Context, send func(msg *bean.JSONRPCMessage) error) context.Context {
+	return context.WithValue(ctx, mcpSenderKey{}, send)
+}
+
+// notify 向客户端推送消息，优先使用当前请求的流
+func (s *mcpSession) notify(ctx context.Context, msg *bean.JSONRPCMessage) error {
+	if send, ok := ctx.Value(mcpSenderKey{}).(func(msg *bean.JSONRPCMessage) error); ok {
+		return send(msg)
+	}
+	return s.send(msg)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tung/mcp/internal/bean"
+)
+
+// 旧版 HTTP+SSE 传输的路由
+const (
+	mcpSSEPath      = "/sse"
+	mcpMessagesPath = "/messages"
+)
+
+// handleSSE 处理旧版HTTP+SSE传输的GET请求，建立会话并推送endpoint事件
+func (h *mcpHandler) handleSSE(c *gin.Context) {
+	sess := h.createHTTPSession(c)
+	if sess == nil {
+		return
+	}
+	defer h.sessions.Delete(sess.id)
+
+	ch, _ := sess.stream.attach()
+	defer sess.stream.detach(ch)
+
+	writeEventStreamHeaders(c)
+
+	// 客户端通过endpoint事件得知POST消息的地址
+	endpoint := fmt.Sprintf("%s?sessionId=%s", mcpMessagesPath, sess.id)
+	if err := writeSSEEvent(c, "endpoint", endpoint); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(mcpKeepAliveSeconds * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-ch:
+			if err := writeSSEMessage(c, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+			h.touchHTTPSession(sess)
+		case <-sess.done():
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// handleSSEMessage 处理旧版HTTP+SSE传输的POST消息，结果通过SSE流返回
+func (h *mcpHandler) handleSSEMessage(c *gin.Context) {
+	sess, found := h.getHTTPSession(c, c.Query("sessionId"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在或已结束"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取请求失败"})
+		return
+	}
+
+	msg, rpcErr := parseJSONRPCMessage(body)
+	if rpcErr != nil {
+		sess.send(bean.NewJSONRPCErrorResponse(nil, rpcErr))
+		c.Status(http.StatusAccepted)
+		return
+	}
+	if !checkScopes(c, mcpRequiredScopes(msg)) {
+		return
+	}
+
+	ctx := withClientIP(sess.ctx, c)
+	handle := func() {
+		if resp := h.handleMessage(ctx, sess.mcpSession, msg); resp != nil {
+			sess.send(resp)
+		}
+	}
+
+	// 通知和initialize同步处理以保证顺序，其余请求异步处理，耗时的工具调用不会阻塞POST请求
+	if !msg.IsRequest() || msg.Method == "initialize" {
+		handle()
+	} else {
+		go handle()
+	}
+	c.Status(http.StatusAccepted)
+}
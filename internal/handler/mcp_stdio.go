@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// stdioMaxMessageSize 单条stdio消息的最大长度
+const stdioMaxMessageSize = 10 * 1024 * 1024
+
+// ServeStdio 通过标准输入输出提供MCP服务，每行一条JSON-RPC消息
+func (h *mcpHandler) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(out)
+	send := func(msg *bean.JSONRPCMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return encoder.Encode(msg)
+	}
+
+	sess := newMCPSession("stdio", send)
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), stdioMaxMessageSize)
+
+	var wg sync.WaitGroup
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		msg, rpcErr := parseJSONRPCMessage(line)
+		if rpcErr != nil {
+			send(bean.NewJSONRPCErrorResponse(nil, rpcErr))
+			continue
+		}
+
+		// 通知和initialize按顺序同步处理，其余请求并发处理，避免耗时的工具调用阻塞读取
+		if !msg.IsRequest() || msg.Method == "initialize" {
+			if resp := h.handleMessage(ctx, sess, msg); resp != nil {
+				send(resp)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp := h.handleMessage(ctx, sess, msg); resp != nil {
+				send(resp)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return scanner.Err()
+}
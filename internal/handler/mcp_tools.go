@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/jsonschema"
+)
+
+// mcpToolFunc 工具的执行函数
+type mcpToolFunc func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+
+// mcpToolEntry 已注册的工具
+type mcpToolEntry struct {
+	tool        bean.MCPTool
+	inputSchema *jsonschema.Schema
+	call        mcpToolFunc
+	format      func(output interface{}) string
+}
+
+// mcpToolRegistry MCP工具注册表
+type mcpToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*mcpToolEntry
+	names []string
+}
+
+// newMCPToolRegistry 创建新的工具注册表
+func newMCPToolRegistry() *mcpToolRegistry {
+	return &mcpToolRegistry{
+		tools: make(map[string]*mcpToolEntry),
+	}
+}
+
+// registerMCPTool 注册带类型的工具，输入输出Schema分别由In和Out类型生成，
+// format将输出转换为结果中的可读文本，为nil时使用JSON文本
+func registerMCPTool[In, Out any](r *mcpToolRegistry, name, description string, handler func(ctx context.Context, in In) (Out, error), format func(out Out) string) {
+	inputSchema := jsonschema.For[In]()
+	entry := &mcpToolEntry{
+		tool: bean.MCPTool{
+			Name:         name,
+			Description:  description,
+			InputSchema:  inputSchema,
+			OutputSchema: jsonschema.For[Out](),
+		},
+		inputSchema: inputSchema,
+		call: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+			var in In
+			if err := json.Unmarshal(arguments, &in); err != nil {
+				return nil, &jsonschema.ValidationError{Message: "参数格式错误: " + err.Error()}
+			}
+			out, err := handler(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			// nil切片序列化为null，不符合outputSchema中必填的数组字段
+			return jsonschema.Normalize(out), nil
+		},
+	}
+	if format != nil {
+		entry.format = func(output interface{}) string {
+			return format(output.(Out))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[name]; !exists {
+		r.names = append(r.names, name)
+	}
+	r.tools[name] = entry
+}
+
+// list 按注册顺序返回所有工具定义
+func (r *mcpToolRegistry) list() []bean.MCPTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]bean.MCPTool, 0, len(r.names))
+	for _, name := range r.names {
+		tools = append(tools, r.tools[name].tool)
+	}
+	return tools
+}
+
+// get 按名称查找工具
+func (r *mcpToolRegistry) get(name string) (*mcpToolEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, found := r.tools[name]
+	return entry, found
+}
+
+// invoke 按输入Schema校验参数后调用工具，参数不合法时返回*jsonschema.ValidationError
+func (e *mcpToolEntry) invoke(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+	if err := e.inputSchema.Validate(arguments); err != nil {
+		return nil, err
+	}
+	return e.call(ctx, arguments)
+}
+
+// result 将工具输出转换为tools/call结果
+func (e *mcpToolEntry) result(output interface{}) (bean.MCPCallToolResult, error) {
+	if e.format != nil {
+		return bean.NewMCPToolResult(e.format(output), output), nil
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return bean.MCPCallToolResult{}, fmt.Errorf("结果序列化失败: %w", err)
+	}
+	return bean.NewMCPToolResult(string(data), output), nil
+}
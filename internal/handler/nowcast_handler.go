@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logic"
+)
+
+// NowcastHandler 短时降水处理器接口
+type NowcastHandler interface {
+	GetNowcast(c *gin.Context)
+	RegisterRoutes(router *gin.Engine)
+}
+
+// nowcastHandler 短时降水处理器实现
+type nowcastHandler struct {
+	nowcastLogic logic.NowcastLogic
+}
+
+// NewNowcastHandler 创建新的短时降水处理器
+func NewNowcastHandler(nowcastLogic logic.NowcastLogic) NowcastHandler {
+	return &nowcastHandler{
+		nowcastLogic: nowcastLogic,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *nowcastHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/nowcast", h.GetNowcast)
+}
+
+// GetNowcast 获取未来2小时逐分钟降水预报
+func (h *nowcastHandler) GetNowcast(c *gin.Context) {
+	var req bean.NowcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "无效的请求参数，需要latitude（-90~90）和longitude（-180~180）",
+		})
+		return
+	}
+	if _, err := bean.ParseCRS(req.CRS); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.nowcastLogic.GetNowcast(c.Request.Context(), *req.Latitude, *req.Longitude, req.CRS)
+	if err != nil {
+		c.JSON(nowcastErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// nowcastErrorStatus 根据查询短时降水的错误选择HTTP状态码，上游接口的故障返回502
+func nowcastErrorStatus(err error) int {
+	var notFound *bean.LocationNotFoundError
+	switch {
+	case errors.Is(err, bean.ErrUnknownCRS):
+		return http.StatusBadRequest
+	case errors.As(err, &notFound):
+		return http.StatusNotFound
+	case errors.Is(err, bean.ErrNowcastFailed):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
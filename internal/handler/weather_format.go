@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// formatWeatherText 将天气数据格式化为便于阅读的文本
+func formatWeatherText(response *bean.WeatherResponse) string {
+	var b strings.Builder
+
+	current := response.CurrentConditions
+	fmt.Fprintf(&b, "%s（%s）\n", response.Location, response.LocationKey)
+	if region := response.Region; region != nil {
+		fmt.Fprintf(&b, "所在区划：%s（区域编码 %s）\n", strings.Join(nonEmpty(region.Province, region.City, region.District, region.Township), " "), region.Adcode)
+	}
+	fmt.Fprintf(&b, "当前天气：%s，%.0f°%s，相对湿度 %d%%", current.WeatherText, current.Temperature.Value, current.Temperature.Unit, current.RelativeHumidity)
+	if current.ObservationTime != "" {
+		fmt.Fprintf(&b, "（发布时间 %s）", current.ObservationTime)
+	}
+	b.WriteString("\n")
+
+	if len(response.HourlyForecast) > 0 {
+		b.WriteString("逐小时预报：\n")
+		for _, hour := range response.HourlyForecast {
+			fmt.Fprintf(&b, "- %s：%s，%.0f°%s", hour.RelativeTime, hour.WeatherText, hour.Temperature.Value, hour.Temperature.Unit)
+			if hour.PrecipitationProbability > 0 {
+				fmt.Fprintf(&b, "，降水概率 %d%%", hour.PrecipitationProbability)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(response.DailyForecast) > 0 {
+		b.WriteString("逐日预报：\n")
+		for _, day := range response.DailyForecast {
+			fmt.Fprintf(&b, "- %s：%s转%s，%.0f~%.0f°%s\n", day.Date, day.WeatherTextDay, day.WeatherTextNight, day.TempMin.Value, day.TempMax.Value, day.TempMax.Unit)
+		}
+	}
+
+	if len(response.Warnings) > 0 {
+		b.WriteString("天气预警：\n")
+		for _, warning := range response.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning.Title)
+		}
+	}
+
+	if response.Provider != "" {
+		fmt.Fprintf(&b, "数据来源：%s\n", response.Provider)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// nonEmpty 返回非空的字符串
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// formatWeatherBatchText 将批量天气数据格式化为便于阅读的文本
+func formatWeatherBatchText(response *bean.WeatherBatchResponse) string {
+	sections := make([]string, 0, len(response.Results))
+	for _, item := range response.Results {
+		if item.Weather == nil {
+			sections = append(sections, fmt.Sprintf("%s：查询失败，%s", item.Location, item.Error))
+			continue
+		}
+		sections = append(sections, formatWeatherText(item.Weather))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// formatLocationSearchText 将行政区划搜索结果格式化为便于阅读的文本
+func formatLocationSearchText(response *bean.LocationSearchResponse) string {
+	if len(response.Results) == 0 {
+		return fmt.Sprintf("没有找到与\"%s\"匹配的行政区划", response.Query)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "与\"%s\"匹配的行政区划：\n", response.Query)
+	for _, d := range response.Results {
+		fmt.Fprintf(&b, "- %s（区域编码 %s", d.FullName, d.Adcode)
+		if d.Citycode != "" {
+			fmt.Fprintf(&b, "，城市编码 %s", d.Citycode)
+		}
+		b.WriteString("）\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatNowcastText 将短时降水预报格式化为便于阅读的文本
+func formatNowcastText(response *bean.NowcastResponse) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%.4f,%.4f 未来2小时降水预报", response.Latitude, response.Longitude)
+	if response.Description != "" {
+		fmt.Fprintf(&b, "：%s", response.Description)
+	}
+	b.WriteString("\n")
+
+	switch {
+	case response.RainStartsIn == nil:
+		b.WriteString("未来2小时没有降水\n")
+	case *response.RainStartsIn == 0:
+		fmt.Fprintf(&b, "正在降水，最大强度 %.2f 毫米/小时\n", response.MaxIntensity)
+	default:
+		fmt.Fprintf(&b, "约 %d 分钟后开始降水，最大强度 %.2f 毫米/小时\n", *response.RainStartsIn, response.MaxIntensity)
+	}
+
+	if len(response.Probability) > 0 {
+		parts := make([]string, len(response.Probability))
+		for i, p := range response.Probability {
+			parts[i] = fmt.Sprintf("%d-%d分钟 %d%%", i*30, (i+1)*30, p)
+		}
+		fmt.Fprintf(&b, "降水概率：%s\n", strings.Join(parts, "，"))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
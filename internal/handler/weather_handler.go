@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tung/mcp/internal/bean"
@@ -34,16 +36,40 @@ func (h *weatherHandler) RegisterRoutes(router *gin.Engine) {
 // GetHourlyWeather 获取每小时天气预报
 func (h *weatherHandler) GetHourlyWeather(c *gin.Context) {
 	var req bean.WeatherRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil || !coordinatesValid(req.Latitude, req.Longitude) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求参数",
+			"error": "无效的请求参数，需要location，或者latitude（-90~90）和longitude（-180~180），启用IP定位时可以都省略",
+		})
+		return
+	}
+	if _, err := bean.ParseCRS(req.CRS); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
 		})
 		return
 	}
 
-	response, err := h.weatherLogic.GetHourlyWeather(req.Location)
+	var response *bean.WeatherResponse
+	var err error
+	switch {
+	case req.Latitude != nil:
+		response, err = h.weatherLogic.GetHourlyWeatherAt(c.Request.Context(), *req.Latitude, *req.Longitude, req.CRS)
+	case strings.TrimSpace(req.Location) == "":
+		response, err = h.weatherLogic.GetHourlyWeatherByIP(c.Request.Context(), c.ClientIP())
+	default:
+		response, err = h.weatherLogic.GetHourlyWeather(c.Request.Context(), req.Location)
+	}
+	var ambiguous *bean.AmbiguousLocationError
+	if errors.As(err, &ambiguous) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "地点对应多个行政区划",
+			"location":   ambiguous.Location,
+			"candidates": ambiguous.Candidates,
+		})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(weatherErrorStatus(err), gin.H{
 			"error": err.Error(),
 		})
 		return
@@ -51,3 +77,22 @@ func (h *weatherHandler) GetHourlyWeather(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// coordinatesValid 检查经纬度是否成对提供。经纬度优先于地点，两者都省略时按客户端IP定位
+func coordinatesValid(lat, lon *float64) bool {
+	return (lat == nil) == (lon == nil)
+}
+
+// weatherErrorStatus 根据查询天气的错误选择HTTP状态码
+func weatherErrorStatus(err error) int {
+	var notFound *bean.LocationNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		return http.StatusNotFound
+	case errors.Is(err, bean.ErrLocationRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, bean.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
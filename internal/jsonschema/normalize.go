@@ -0,0 +1,61 @@
+package jsonschema
+
+import "reflect"
+
+// Normalize 返回v的副本，其中nil切片替换为空切片、nil map替换为空map，
+// 使序列化结果符合For生成的Schema：没有omitempty的数组和对象字段是必填的，不能为null。
+// 返回副本而不是原地修改，v可能是多个请求共享的缓存数据
+func Normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return normalize(reflect.ValueOf(v)).Interface()
+}
+
+// normalize 递归复制值并替换其中的nil切片和map
+func normalize(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(normalize(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(normalize(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				copied.Field(i).Set(normalize(v.Field(i)))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		// []byte序列化为字符串，不需要处理
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(normalize(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), normalize(iter.Value()))
+		}
+		return copied
+	default:
+		return v
+	}
+}
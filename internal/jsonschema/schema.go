@@ -0,0 +1,167 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema JSON Schema 描述，只包含本项目用到的关键字
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	EnumNames            []string           `json:"enumNames,omitempty"` // 枚举值的显示名称，用于elicitation
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// For 根据类型T生成JSON Schema
+//
+// 字段名取自json标签，没有omitempty的字段视为必填；
+// description标签提供字段说明，jsonschema标签提供约束，
+// 例如 `jsonschema:"minimum=-90,maximum=90"`、`jsonschema:"enum=a|b"`
+func For[T any]() *Schema {
+	return Generate(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Generate 根据反射类型生成JSON Schema
+func Generate(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: Generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: Generate(t.Elem())}
+	case reflect.Struct:
+		return generateStruct(t)
+	default:
+		// interface{} 等类型不做限制
+		return &Schema{}
+	}
+}
+
+// generateStruct 生成结构体的JSON Schema
+func generateStruct(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty := parseJSONTag(field)
+		if name == "-" {
+			continue
+		}
+
+		// 匿名嵌入的结构体字段展开到当前层级
+		if field.Anonymous && name == "" {
+			embedded := Generate(field.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := Generate(field.Type)
+		prop.Description = field.Tag.Get("description")
+		applyConstraints(prop, field.Tag.Get("jsonschema"))
+		s.Properties[name] = prop
+
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// parseJSONTag 解析json标签，返回字段名和是否omitempty
+func parseJSONTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	parts := strings.Split(tag, ",")
+	omitempty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty
+}
+
+// applyConstraints 将jsonschema标签中的约束写入Schema
+func applyConstraints(s *Schema, tag string) {
+	if tag == "" {
+		return
+	}
+
+	for _, item := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(item, "=")
+		switch key {
+		case "minimum":
+			s.Minimum = parseFloat(value)
+		case "maximum":
+			s.Maximum = parseFloat(value)
+		case "minLength":
+			s.MinLength = parseInt(value)
+		case "maxLength":
+			s.MaxLength = parseInt(value)
+		case "minItems":
+			s.MinItems = parseInt(value)
+		case "maxItems":
+			s.MaxItems = parseInt(value)
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				s.Enum = append(s.Enum, v)
+			}
+		}
+	}
+}
+
+// parseFloat 解析浮点数约束
+func parseFloat(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// parseInt 解析整数约束
+func parseInt(value string) *int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
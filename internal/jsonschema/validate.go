@@ -0,0 +1,192 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError 参数校验错误
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate 校验JSON数据是否符合Schema
+func (s *Schema) Validate(data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return &ValidationError{Message: "JSON格式错误: " + err.Error()}
+	}
+	return s.validate("", value)
+}
+
+// validate 递归校验数据
+func (s *Schema) validate(path string, value interface{}) error {
+	if err := s.validateType(path, value); err != nil {
+		return err
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("取值必须是 %v 之一", s.Enum)}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(path, v)
+	case []interface{}:
+		return s.validateArray(path, v)
+	case string:
+		return s.validateString(path, v)
+	case json.Number:
+		return s.validateNumber(path, v)
+	}
+	return nil
+}
+
+// validateType 校验数据类型
+func (s *Schema) validateType(path string, value interface{}) error {
+	if s.Type == "" {
+		return nil
+	}
+
+	ok := false
+	switch s.Type {
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(json.Number)
+	case "integer":
+		if n, isNumber := value.(json.Number); isNumber {
+			_, err := n.Int64()
+			ok = err == nil
+		}
+	case "null":
+		ok = value == nil
+	}
+
+	if !ok {
+		return &ValidationError{Path: path, Message: "类型必须是" + s.Type}
+	}
+	return nil
+}
+
+// validateObject 校验对象的必填字段和各属性
+func (s *Schema) validateObject(path string, obj map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, found := obj[name]; !found {
+			return &ValidationError{Path: joinPath(path, name), Message: "缺少必要参数"}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		prop, found := s.Properties[k]
+		if !found {
+			prop = s.AdditionalProperties
+		}
+		if prop == nil {
+			continue
+		}
+		if err := prop.validate(joinPath(path, k), obj[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateArray 校验数组长度和元素
+func (s *Schema) validateArray(path string, arr []interface{}) error {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("至少需要%d个元素", *s.MinItems)}
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("最多允许%d个元素", *s.MaxItems)}
+	}
+	if s.Items == nil {
+		return nil
+	}
+	for i, item := range arr {
+		if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateString 校验字符串长度
+func (s *Schema) validateString(path string, str string) error {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		if length == 0 {
+			return &ValidationError{Path: path, Message: "不能为空"}
+		}
+		return &ValidationError{Path: path, Message: fmt.Sprintf("长度不能少于%d", *s.MinLength)}
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("长度不能超过%d", *s.MaxLength)}
+	}
+	return nil
+}
+
+// validateNumber 校验数值范围
+func (s *Schema) validateNumber(path string, n json.Number) error {
+	f, err := n.Float64()
+	if err != nil {
+		return &ValidationError{Path: path, Message: "无效的数值"}
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("不能小于%v", *s.Minimum)}
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("不能大于%v", *s.Maximum)}
+	}
+	return nil
+}
+
+// inEnum 数据是否在枚举值中
+func (s *Schema) inEnum(value interface{}) bool {
+	for _, e := range s.Enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinPath 拼接字段路径
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return strings.Join([]string{path, name}, ".")
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Level 日志级别，与MCP日志级别（RFC 5424）一致
+type Level int32
+
+// 日志级别，从低到高
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelNotice
+	LevelWarning
+	LevelError
+	LevelCritical
+	LevelAlert
+	LevelEmergency
+)
+
+// levelNames 日志级别名称
+var levelNames = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// String 返回日志级别名称
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", l)
+	}
+	return levelNames[l]
+}
+
+// ParseLevel 解析日志级别名称
+func ParseLevel(name string) (Level, bool) {
+	for i, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return Level(i), true
+		}
+	}
+	return LevelInfo, false
+}
+
+// Entry 一条日志
+type Entry struct {
+	Level   Level
+	Logger  string
+	Message string
+}
+
+// Emitter 接收日志的回调，例如把日志转发给发起请求的MCP客户端
+type Emitter func(ctx context.Context, entry Entry)
+
+// emitterKey 上下文中Emitter的键
+type emitterKey struct{}
+
+// WithEmitter 将Emitter放入上下文，之后使用该上下文记录的日志都会交给它
+func WithEmitter(ctx context.Context, emitter Emitter) context.Context {
+	return context.WithValue(ctx, emitterKey{}, emitter)
+}
+
+// minLevel 写入标准错误输出的最低级别
+var minLevel atomic.Int32
+
+func init() {
+	minLevel.Store(int32(LevelInfo))
+}
+
+// SetLevel 设置写入标准错误输出的最低级别
+func SetLevel(level Level) {
+	minLevel.Store(int32(level))
+}
+
+// Log 记录一条日志，写入标准错误输出并交给上下文中的Emitter
+func Log(ctx context.Context, level Level, logger, format string, args ...interface{}) {
+	entry := Entry{
+		Level:   level,
+		Logger:  logger,
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	if level >= Level(minLevel.Load()) {
+		log.Printf("[%s] %s: %s", entry.Level, entry.Logger, entry.Message)
+	}
+
+	if ctx == nil {
+		return
+	}
+	if emit, ok := ctx.Value(emitterKey{}).(Emitter); ok {
+		emit(ctx, entry)
+	}
+}
+
+// Debugf 记录debug级别日志
+func Debugf(ctx context.Context, logger, format string, args ...interface{}) {
+	Log(ctx, LevelDebug, logger, format, args...)
+}
+
+// Infof 记录info级别日志
+func Infof(ctx context.Context, logger, format string, args ...interface{}) {
+	Log(ctx, LevelInfo, logger, format, args...)
+}
+
+// Warningf 记录warning级别日志
+func Warningf(ctx context.Context, logger, format string, args ...interface{}) {
+	Log(ctx, LevelWarning, logger, format, args...)
+}
+
+// Errorf 记录error级别日志
+func Errorf(ctx context.Context, logger, format string, args ...interface{}) {
+	Log(ctx, LevelError, logger, format, args...)
+}
+
+// Fatalf 记录critical级别日志后退出进程
+func Fatalf(ctx context.Context, logger, format string, args ...interface{}) {
+	Log(ctx, LevelCritical, logger, format, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/service"
+)
+
+// LocationLogic 地点逻辑接口
+type LocationLogic interface {
+	SearchLocations(query string, limit int) []bean.District
+}
+
+// locationLogic 地点逻辑实现
+type locationLogic struct {
+	districtService service.DistrictService
+}
+
+// NewLocationLogic 创建新的地点逻辑
+func NewLocationLogic(districtService service.DistrictService) LocationLogic {
+	return &locationLogic{
+		districtService: districtService,
+	}
+}
+
+// SearchLocations 按名称、拼音、拼音首字母或编码搜索行政区划，没有结果时按编辑距离容错
+func (l *locationLogic) SearchLocations(query string, limit int) []bean.District {
+	return l.districtService.Search(query, limit)
+}
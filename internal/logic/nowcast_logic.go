@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/service"
+)
+
+// NowcastLogic 短时降水逻辑接口
+type NowcastLogic interface {
+	GetNowcast(ctx context.Context, lat, lon float64, crs string) (*bean.NowcastResponse, error)
+}
+
+// nowcastLogic 短时降水逻辑实现
+type nowcastLogic struct {
+	nowcastService service.NowcastProvider
+}
+
+// NewNowcastLogic 创建新的短时降水逻辑
+func NewNowcastLogic(nowcastService service.NowcastProvider) NowcastLogic {
+	return &nowcastLogic{
+		nowcastService: nowcastService,
+	}
+}
+
+// GetNowcast 获取未来2小时逐分钟降水预报，坐标转换为WGS-84后查询
+func (l *nowcastLogic) GetNowcast(ctx context.Context, lat, lon float64, crs string) (*bean.NowcastResponse, error) {
+	crs, err := bean.ParseCRS(crs)
+	if err != nil {
+		return nil, err
+	}
+	lat, lon = service.ConvertCoordinates(lat, lon, crs, bean.CRSWGS84)
+	return l.nowcastService.GetNowcast(ctx, lat, lon)
+}
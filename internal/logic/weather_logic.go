@@ -1,28 +1,104 @@
 package logic
 
 import (
+	"context"
+	"fmt"
+	"math"
+
 	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
 	"github.com/tung/mcp/internal/service"
 )
 
+// weatherLogger 天气逻辑的日志名称
+const weatherLogger = "weather"
+
 // WeatherLogic 天气逻辑接口
 type WeatherLogic interface {
-	GetHourlyWeather(location string) (*bean.WeatherResponse, error)
+	Capabilities() bean.ProviderCapabilities
+	GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error)
+	GetHourlyWeatherAt(ctx context.Context, lat, lon float64, crs string) (*bean.WeatherResponse, error)
+	GetHourlyWeatherByIP(ctx context.Context, ip string) (*bean.WeatherResponse, error)
 }
 
 // weatherLogic 天气逻辑实现
 type weatherLogic struct {
-	weatherService service.WeatherService
+	weatherService service.Provider
+	geocoder       service.AmapGeocoder
+	ipLocator      service.IPLocator
 }
 
-// NewWeatherLogic 创建新的天气逻辑
-func NewWeatherLogic(weatherService service.WeatherService) WeatherLogic {
+// NewWeatherLogic 创建新的天气逻辑，geocoder为空时按坐标查询的响应不包含所在行政区划，
+// ipLocator为空时不支持按客户端IP查询
+func NewWeatherLogic(weatherService service.Provider, geocoder service.AmapGeocoder, ipLocator service.IPLocator) WeatherLogic {
 	return &weatherLogic{
 		weatherService: weatherService,
+		geocoder:       geocoder,
+		ipLocator:      ipLocator,
 	}
 }
 
+// Capabilities 返回天气数据源的能力
+func (l *weatherLogic) Capabilities() bean.ProviderCapabilities {
+	return l.weatherService.Capabilities()
+}
+
 // GetHourlyWeather 获取每小时天气预报
-func (l *weatherLogic) GetHourlyWeather(location string) (*bean.WeatherResponse, error) {
-	return l.weatherService.GetHourlyWeather(location)
+func (l *weatherLogic) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	return l.weatherService.GetHourlyWeather(ctx, location)
+}
+
+// GetHourlyWeatherAt 按坐标获取每小时天气预报，并通过逆地理编码附上坐标所在的行政区划，
+// 行政区划的坐标转换回请求的坐标系。坐标统一转换为WGS-84后交给数据源，需要其他坐标系的数据源自行转换。
+// 逆地理编码失败（例如坐标在国外）不影响天气查询
+func (l *weatherLogic) GetHourlyWeatherAt(ctx context.Context, lat, lon float64, crs string) (*bean.WeatherResponse, error) {
+	crs, err := bean.ParseCRS(crs)
+	if err != nil {
+		return nil, err
+	}
+	lat, lon = service.ConvertCoordinates(lat, lon, crs, bean.CRSWGS84)
+	// 与交给数据源的地点使用相同精度，高德数据源的逆地理编码才能命中同一缓存
+	lat, lon = math.Round(lat*1e4)/1e4, math.Round(lon*1e4)/1e4
+
+	var region *bean.AdministrativeArea
+	if l.geocoder != nil {
+		gcjLat, gcjLon := service.ConvertCoordinates(lat, lon, bean.CRSWGS84, bean.CRSGCJ02)
+		area, err := l.geocoder.ReverseGeocode(ctx, gcjLat, gcjLon)
+		if err != nil {
+			logging.Warningf(ctx, weatherLogger, "解析坐标所在行政区划失败 %s: %v", service.FormatCoordinates(lat, lon), err)
+		} else {
+			areaLat, areaLon := service.ConvertCoordinates(area.Latitude, area.Longitude, area.CRS, crs)
+			area.Latitude, area.Longitude = math.Round(areaLat*1e6)/1e6, math.Round(areaLon*1e6)/1e6
+			area.CRS = crs
+		}
+		region = area
+	}
+
+	response, err := l.weatherService.GetHourlyWeather(ctx, service.FormatCoordinates(lat, lon))
+	if err != nil {
+		return nil, err
+	}
+	response.Region = region
+	return response, nil
+}
+
+// GetHourlyWeatherByIP 按客户端IP所在城市获取每小时天气预报，响应附上定位到的城市。
+// 未启用IP定位时返回bean.ErrLocationRequired
+func (l *weatherLogic) GetHourlyWeatherByIP(ctx context.Context, ip string) (*bean.WeatherResponse, error) {
+	if l.ipLocator == nil {
+		return nil, bean.ErrLocationRequired
+	}
+
+	area, err := l.ipLocator.Locate(ctx, ip)
+	if err != nil {
+		logging.Warningf(ctx, weatherLogger, "IP定位失败 %s: %v", ip, err)
+		return nil, fmt.Errorf("IP定位失败: %w", err)
+	}
+
+	response, err := l.weatherService.GetHourlyWeather(ctx, area.Adcode)
+	if err != nil {
+		return nil, err
+	}
+	response.Region = area
+	return response, nil
 }
@@ -1,25 +1,39 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/patrickmn/go-cache"
 	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
 )
 
-// WeatherService 天气服务接口
-type WeatherService interface {
-	GetHourlyWeather(location string) (*bean.WeatherResponse, error)
+// accuWeatherLogger AccuWeather服务的日志名称
+const accuWeatherLogger = "accuweather"
+
+// accuWeatherProvider AccuWeather数据源名称
+const accuWeatherProvider = "accuweather"
+
+func init() {
+	RegisterProvider(accuWeatherProvider, func(cfg ProviderConfig) (Provider, error) {
+		apiKey := cfg.Getenv("ACCUWEATHER_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("未设置ACCUWEATHER_API_KEY环境变量")
+		}
+		return NewAccuWeatherService(apiKey), nil
+	})
 }
 
-// weatherService 天气服务实现
-type weatherService struct {
+// accuWeatherService AccuWeather天气服务实现
+type accuWeatherService struct {
 	apiKey        string
 	baseURL       string
 	locationCache *cache.Cache
@@ -27,8 +41,8 @@ type weatherService struct {
 	cacheFile     string
 }
 
-// NewWeatherService 创建新的天气服务
-func NewWeatherService(apiKey string) WeatherService {
+// NewAccuWeatherService 创建新的AccuWeather天气服务
+func NewAccuWeatherService(apiKey string) Provider {
 	// 创建缓存目录
 	homeDir, _ := os.UserHomeDir()
 	cacheDir := filepath.Join(homeDir, ".cache", "weather")
@@ -55,7 +69,7 @@ func NewWeatherService(apiKey string) WeatherService {
 		}
 	}
 
-	return &weatherService{
+	return &accuWeatherService{
 		apiKey:        apiKey,
 		baseURL:       "http://dataservice.accuweather.com",
 		locationCache: c,
@@ -64,15 +78,33 @@ func NewWeatherService(apiKey string) WeatherService {
 	}
 }
 
+// Name 返回数据源名称
+func (s *accuWeatherService) Name() string {
+	return accuWeatherProvider
+}
+
+// Capabilities 返回数据源能力，AccuWeather提供全球范围的真实逐小时预报
+func (s *accuWeatherService) Capabilities() bean.ProviderCapabilities {
+	return bean.ProviderCapabilities{
+		HourlyData: true,
+		DailyData:  false,
+		Coverage:   bean.ProviderCoverageGlobal,
+		Adcodes:    false,
+	}
+}
+
 // GetHourlyWeather 获取每小时天气预报
-func (s *weatherService) GetHourlyWeather(location string) (*bean.WeatherResponse, error) {
+func (s *accuWeatherService) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
 	// 尝试从缓存获取位置键
 	locationKey, found := s.getCachedLocationKey(location)
-	if !found {
+	if found {
+		logging.Debugf(ctx, accuWeatherLogger, "命中位置键缓存: %s -> %s", location, locationKey)
+	} else {
 		// 如果缓存中没有，则从API获取
 		var err error
-		locationKey, err = s.getLocationKey(location)
+		locationKey, err = s.getLocationKey(ctx, location)
 		if err != nil {
+			logging.Errorf(ctx, accuWeatherLogger, "获取位置键失败 %s: %v", location, err)
 			return nil, fmt.Errorf("获取位置键失败: %w", err)
 		}
 		// 缓存位置键
@@ -80,20 +112,23 @@ func (s *weatherService) GetHourlyWeather(location string) (*bean.WeatherRespons
 	}
 
 	// 获取当前天气状况
-	currentConditions, err := s.getCurrentConditions(locationKey)
+	currentConditions, err := s.getCurrentConditions(ctx, locationKey)
 	if err != nil {
+		logging.Errorf(ctx, accuWeatherLogger, "获取当前天气状况失败 %s: %v", locationKey, err)
 		return nil, fmt.Errorf("获取当前天气状况失败: %w", err)
 	}
 
 	// 获取每小时天气预报
-	hourlyForecast, err := s.getHourlyForecast(locationKey)
+	hourlyForecast, err := s.getHourlyForecast(ctx, locationKey)
 	if err != nil {
+		logging.Errorf(ctx, accuWeatherLogger, "获取每小时天气预报失败 %s: %v", locationKey, err)
 		return nil, fmt.Errorf("获取每小时天气预报失败: %w", err)
 	}
 
 	// 获取位置信息
-	locationInfo, err := s.getLocationInfo(locationKey)
+	locationInfo, err := s.getLocationInfo(ctx, locationKey)
 	if err != nil {
+		logging.Errorf(ctx, accuWeatherLogger, "获取位置信息失败 %s: %v", locationKey, err)
 		return nil, fmt.Errorf("获取位置信息失败: %w", err)
 	}
 
@@ -104,16 +139,28 @@ func (s *weatherService) GetHourlyWeather(location string) (*bean.WeatherRespons
 		Country:           locationInfo[0].Country.LocalizedName,
 		CurrentConditions: s.formatCurrentConditions(currentConditions),
 		HourlyForecast:    s.formatHourlyForecast(hourlyForecast),
+		Provider:          accuWeatherProvider,
 	}
 
 	return response, nil
 }
 
-// getLocationKey 获取位置键
-func (s *weatherService) getLocationKey(location string) (string, error) {
-	url := fmt.Sprintf("%s/locations/v1/cities/search?apikey=%s&q=%s", s.baseURL, s.apiKey, location)
+// getLocationKey 获取位置键，"纬度,经度"形式的WGS-84坐标通过地理位置接口查询，其余按城市名称搜索
+func (s *accuWeatherService) getLocationKey(ctx context.Context, location string) (string, error) {
+	params := url.Values{}
+	params.Add("apikey", s.apiKey)
+	path := "/locations/v1/cities/search"
+	lat, lon, isCoordinates := parseCoordinates(location)
+	if isCoordinates {
+		path = "/locations/v1/cities/geoposition/search"
+		params.Add("q", FormatCoordinates(lat, lon))
+	} else {
+		params.Add("q", location)
+	}
+	requestURL := s.baseURL + path + "?" + params.Encode()
 
-	resp, err := http.Get(url)
+	logging.Infof(ctx, accuWeatherLogger, "请求AccuWeather接口: %s", redactAPIKey(requestURL))
+	resp, err := httpGet(ctx, requestURL)
 	if err != nil {
 		return "", err
 	}
@@ -128,23 +175,31 @@ func (s *weatherService) getLocationKey(location string) (string, error) {
 		return "", err
 	}
 
+	// 地理位置接口返回单个位置对象，城市搜索接口返回位置数组
 	var locations bean.AccuWeatherLocationResponse
-	if err := json.Unmarshal(body, &locations); err != nil {
+	if isCoordinates {
+		locations = make(bean.AccuWeatherLocationResponse, 1)
+		err = json.Unmarshal(body, &locations[0])
+	} else {
+		err = json.Unmarshal(body, &locations)
+	}
+	if err != nil {
 		return "", err
 	}
 
-	if len(locations) == 0 {
-		return "", fmt.Errorf("未找到位置: %s", location)
+	if len(locations) == 0 || locations[0].Key == "" {
+		return "", &bean.LocationNotFoundError{Location: location}
 	}
 
 	return locations[0].Key, nil
 }
 
 // getLocationInfo 获取位置信息
-func (s *weatherService) getLocationInfo(locationKey string) (bean.AccuWeatherLocationResponse, error) {
+func (s *accuWeatherService) getLocationInfo(ctx context.Context, locationKey string) (bean.AccuWeatherLocationResponse, error) {
 	url := fmt.Sprintf("%s/locations/v1/%s?apikey=%s", s.baseURL, locationKey, s.apiKey)
 
-	resp, err := http.Get(url)
+	logging.Infof(ctx, accuWeatherLogger, "请求AccuWeather接口: %s", redactAPIKey(url))
+	resp, err := httpGet(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -176,10 +231,11 @@ func (s *weatherService) getLocationInfo(locationKey string) (bean.AccuWeatherLo
 }
 
 // getCurrentConditions 获取当前天气状况
-func (s *weatherService) getCurrentConditions(locationKey string) (bean.AccuWeatherCurrentConditionsResponse, error) {
+func (s *accuWeatherService) getCurrentConditions(ctx context.Context, locationKey string) (bean.AccuWeatherCurrentConditionsResponse, error) {
 	url := fmt.Sprintf("%s/currentconditions/v1/%s?apikey=%s", s.baseURL, locationKey, s.apiKey)
 
-	resp, err := http.Get(url)
+	logging.Infof(ctx, accuWeatherLogger, "请求AccuWeather接口: %s", redactAPIKey(url))
+	resp, err := httpGet(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -203,10 +259,11 @@ func (s *weatherService) getCurrentConditions(locationKey string) (bean.AccuWeat
 }
 
 // getHourlyForecast 获取每小时天气预报
-func (s *weatherService) getHourlyForecast(locationKey string) (bean.AccuWeatherHourlyForecastResponse, error) {
+func (s *accuWeatherService) getHourlyForecast(ctx context.Context, locationKey string) (bean.AccuWeatherHourlyForecastResponse, error) {
 	url := fmt.Sprintf("%s/forecasts/v1/hourly/12hour/%s?apikey=%s&metric=true", s.baseURL, locationKey, s.apiKey)
 
-	resp, err := http.Get(url)
+	logging.Infof(ctx, accuWeatherLogger, "请求AccuWeather接口: %s", redactAPIKey(url))
+	resp, err := httpGet(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +287,7 @@ func (s *weatherService) getHourlyForecast(locationKey string) (bean.AccuWeather
 }
 
 // formatCurrentConditions 格式化当前天气状况
-func (s *weatherService) formatCurrentConditions(currentConditions bean.AccuWeatherCurrentConditionsResponse) bean.CurrentConditions {
+func (s *accuWeatherService) formatCurrentConditions(currentConditions bean.AccuWeatherCurrentConditionsResponse) bean.CurrentConditions {
 	if len(currentConditions) == 0 {
 		return bean.CurrentConditions{}
 	}
@@ -249,7 +306,7 @@ func (s *weatherService) formatCurrentConditions(currentConditions bean.AccuWeat
 }
 
 // formatHourlyForecast 格式化每小时天气预报
-func (s *weatherService) formatHourlyForecast(hourlyForecast bean.AccuWeatherHourlyForecastResponse) []bean.HourlyForecast {
+func (s *accuWeatherService) formatHourlyForecast(hourlyForecast bean.AccuWeatherHourlyForecastResponse) []bean.HourlyForecast {
 	result := make([]bean.HourlyForecast, len(hourlyForecast))
 
 	for i, hour := range hourlyForecast {
@@ -260,6 +317,7 @@ func (s *weatherService) formatHourlyForecast(hourlyForecast bean.AccuWeatherHou
 				}
 				return ""
 			}()),
+			DateTime: forecastDateTime(hour.DateTime),
 			Temperature: bean.Temperature{
 				Value: hour.Temperature.Value,
 				Unit:  hour.Temperature.Unit,
@@ -275,7 +333,7 @@ func (s *weatherService) formatHourlyForecast(hourlyForecast bean.AccuWeatherHou
 }
 
 // getCachedLocationKey 从缓存获取位置键
-func (s *weatherService) getCachedLocationKey(location string) (string, bool) {
+func (s *accuWeatherService) getCachedLocationKey(location string) (string, bool) {
 	if value, found := s.locationCache.Get(location); found {
 		return value.(string), true
 	}
@@ -283,11 +341,13 @@ func (s *weatherService) getCachedLocationKey(location string) (string, bool) {
 }
 
 // cacheLocationKey 缓存位置键
-func (s *weatherService) cacheLocationKey(location, locationKey string) {
+func (s *accuWeatherService) cacheLocationKey(location, locationKey string) {
 	// 添加到内存缓存
 	s.locationCache.Set(location, locationKey, cache.NoExpiration)
 
-	// 保存到文件
+	// 保存到文件，读取、更新和写回期间持有文件锁
+	unlock := lockCacheFile(s.cacheFile)
+	defer unlock()
 	cacheData := make(map[string]string)
 
 	// 尝试从文件加载现有缓存
@@ -304,6 +364,6 @@ func (s *weatherService) cacheLocationKey(location, locationKey string) {
 	// 保存回文件
 	data, err := json.Marshal(cacheData)
 	if err == nil {
-		os.WriteFile(s.cacheFile, data, 0644)
+		writeCacheFile(s.cacheFile, data)
 	}
 }
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// adcodeLogger 区域编码解析的日志名称
+const adcodeLogger = "adcode"
+
+// adcodeProvider 为只认坐标或地名的数据源解析高德区域编码：区域编码和
+// "名称 (区域编码)"形式的地点先经高德地理编码换成WGS-84坐标再查询
+type adcodeProvider struct {
+	provider        Provider
+	districtService DistrictService
+	geocoder        AmapGeocoder
+}
+
+// NewAdcodeProvider 创建能按区域编码查询的数据源
+func NewAdcodeProvider(provider Provider, districtService DistrictService, geocoder AmapGeocoder) Provider {
+	return &adcodeProvider{
+		provider:        provider,
+		districtService: districtService,
+		geocoder:        geocoder,
+	}
+}
+
+// Name 返回被包装的数据源名称
+func (p *adcodeProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Capabilities 返回被包装数据源的能力，并支持按区域编码查询
+func (p *adcodeProvider) Capabilities() bean.ProviderCapabilities {
+	caps := p.provider.Capabilities()
+	caps.Adcodes = true
+	return caps
+}
+
+// GetHourlyWeather 区域编码换成坐标后查询，其他地点原样交给被包装的数据源
+func (p *adcodeProvider) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	adcode, found := explicitAdcode(location)
+	if !found {
+		return p.provider.GetHourlyWeather(ctx, location)
+	}
+
+	district, found := p.districtService.Get(adcode)
+	if !found {
+		return nil, &bean.LocationNotFoundError{Location: location}
+	}
+	resolved, err := p.geocoder.Geocode(ctx, district.FullName)
+	if err != nil {
+		return nil, fmt.Errorf("解析区域编码失败: %w", err)
+	}
+
+	lat, lon := ConvertCoordinates(resolved.Latitude, resolved.Longitude, bean.CRSGCJ02, bean.CRSWGS84)
+	coordinates := FormatCoordinates(lat, lon)
+	logging.Debugf(ctx, adcodeLogger, "区域编码%s(%s)解析为坐标%s", adcode, district.FullName, coordinates)
+	return p.provider.GetHourlyWeather(ctx, coordinates)
+}
+
+// explicitAdcode 返回地点中明确给出的区域编码，支持区域编码和"名称 (区域编码)"形式
+func explicitAdcode(location string) (string, bool) {
+	location = strings.TrimSpace(location)
+	if adcodePattern.MatchString(location) {
+		return location, true
+	}
+	if matches := adcodeSuffixPattern.FindStringSubmatch(location); matches != nil {
+		return matches[1], true
+	}
+	return "", false
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// AmapGeocoder 高德地理编码服务接口，将自由文本地址解析为区域编码，或将坐标解析为所在行政区划
+type AmapGeocoder interface {
+	Geocode(ctx context.Context, address string) (*bean.ResolvedLocation, error)
+	ReverseGeocode(ctx context.Context, lat, lon float64) (*bean.AdministrativeArea, error)
+}
+
+// amapGeocoder 高德地理编码服务实现
+type amapGeocoder struct {
+	apiKey        string
+	baseURL       string
+	regeoURL      string
+	locationCache *cache.Cache
+	regeoCache    *cache.Cache
+	cacheFile     string
+}
+
+// NewAmapGeocoder 创建新的高德地理编码服务，解析结果缓存在内存和文件中
+func NewAmapGeocoder(apiKey string) AmapGeocoder {
+	// 创建缓存目录
+	homeDir, _ := os.UserHomeDir()
+	cacheDir := filepath.Join(homeDir, ".cache", "amap_weather")
+	cacheFile := filepath.Join(cacheDir, "geocode_cache.json")
+
+	// 确保缓存目录存在
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		os.MkdirAll(cacheDir, 0755)
+	}
+
+	// 初始化缓存
+	c := cache.New(cache.NoExpiration, 0)
+
+	// 尝试从文件加载缓存
+	if data, err := os.ReadFile(cacheFile); err == nil {
+		var cacheData map[string]bean.ResolvedLocation
+		if err := json.Unmarshal(data, &cacheData); err == nil {
+			for k, v := range cacheData {
+				c.Set(k, v, cache.NoExpiration)
+			}
+		}
+	}
+
+	return &amapGeocoder{
+		apiKey:        apiKey,
+		baseURL:       "https://restapi.amap.com/v3/geocode/geo",
+		regeoURL:      "https://restapi.amap.com/v3/geocode/regeo",
+		locationCache: c,
+		regeoCache:    cache.New(24*time.Hour, 1*time.Hour),
+		cacheFile:     cacheFile,
+	}
+}
+
+// Geocode 解析地址，优先使用缓存。找不到地址时返回*bean.LocationNotFoundError
+func (g *amapGeocoder) Geocode(ctx context.Context, address string) (*bean.ResolvedLocation, error) {
+	key := strings.TrimSpace(address)
+	if cached, found := g.locationCache.Get(key); found {
+		resolved := cached.(bean.ResolvedLocation)
+		logging.Debugf(ctx, amapLogger, "命中地理编码缓存: %s -> %s (%s)", address, resolved.Adcode, resolved.FormattedAddress)
+		return &resolved, nil
+	}
+
+	params := url.Values{}
+	params.Add("key", g.apiKey)
+	params.Add("address", key)
+	params.Add("output", "JSON")
+
+	logging.Infof(ctx, amapLogger, "请求高德地理编码接口: address=%s", key)
+	resp, err := httpGet(ctx, g.baseURL+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var geocodeResp bean.AmapGeocodeResponse
+	if err := json.Unmarshal(body, &geocodeResp); err != nil {
+		return nil, err
+	}
+
+	if geocodeResp.Status != "1" {
+		return nil, fmt.Errorf("API返回错误: %s", geocodeResp.Info)
+	}
+
+	geocode, found := bestAmapGeocode(geocodeResp.Geocodes)
+	if !found {
+		return nil, &bean.LocationNotFoundError{Location: address}
+	}
+
+	resolved := bean.ResolvedLocation{
+		Query:            key,
+		Adcode:           string(geocode.Adcode),
+		FormattedAddress: string(geocode.FormattedAddress),
+		Level:            string(geocode.Level),
+	}
+	if lonStr, latStr, ok := strings.Cut(string(geocode.Location), ","); ok {
+		resolved.Longitude, _ = strconv.ParseFloat(lonStr, 64)
+		resolved.Latitude, _ = strconv.ParseFloat(latStr, 64)
+	}
+	logging.Infof(ctx, amapLogger, "地理编码: %s -> %s (%s)", address, resolved.Adcode, resolved.FormattedAddress)
+
+	g.cacheLocation(key, resolved)
+	return &resolved, nil
+}
+
+// ReverseGeocode 将坐标（GCJ-02）解析为所在行政区划，结果按4位小数的坐标在内存中缓存。
+// 坐标不在中国境内时返回*bean.LocationNotFoundError
+func (g *amapGeocoder) ReverseGeocode(ctx context.Context, lat, lon float64) (*bean.AdministrativeArea, error) {
+	key := FormatCoordinates(lat, lon)
+	if cached, found := g.regeoCache.Get(key); found {
+		area := cached.(bean.AdministrativeArea)
+		logging.Debugf(ctx, amapLogger, "命中逆地理编码缓存: %s -> %s", key, area.Adcode)
+		return &area, nil
+	}
+
+	params := url.Values{}
+	params.Add("key", g.apiKey)
+	// 高德坐标为"经度,纬度"
+	params.Add("location", strconv.FormatFloat(lon, 'f', 6, 64)+","+strconv.FormatFloat(lat, 'f', 6, 64))
+	params.Add("output", "JSON")
+
+	logging.Infof(ctx, amapLogger, "请求高德逆地理编码接口: location=%s", key)
+	resp, err := httpGet(ctx, g.regeoURL+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var regeoResp bean.AmapRegeoResponse
+	if err := json.Unmarshal(body, &regeoResp); err != nil {
+		return nil, err
+	}
+
+	if regeoResp.Status != "1" {
+		return nil, fmt.Errorf("API返回错误: %s", regeoResp.Info)
+	}
+
+	component := regeoResp.Regeocode.AddressComponent
+	if adcode := string(component.Adcode); len(adcode) != 6 || !isDigits(adcode) {
+		return nil, &bean.LocationNotFoundError{Location: key}
+	}
+
+	area := bean.AdministrativeArea{
+		Country:          string(component.Country),
+		Province:         string(component.Province),
+		City:             string(component.City),
+		District:         string(component.District),
+		Township:         string(component.Township),
+		Adcode:           string(component.Adcode),
+		Citycode:         string(component.Citycode),
+		FormattedAddress: string(regeoResp.Regeocode.FormattedAddress),
+		Latitude:         math.Round(lat*1e6) / 1e6,
+		Longitude:        math.Round(lon*1e6) / 1e6,
+		CRS:              bean.CRSGCJ02,
+	}
+	logging.Infof(ctx, amapLogger, "逆地理编码: %s -> %s (%s)", key, area.Adcode, area.FormattedAddress)
+
+	g.regeoCache.Set(key, area, cache.DefaultExpiration)
+	return &area, nil
+}
+
+// cacheLocation 缓存解析结果并保存到文件
+func (g *amapGeocoder) cacheLocation(key string, resolved bean.ResolvedLocation) {
+	g.locationCache.Set(key, resolved, cache.NoExpiration)
+
+	// 将缓存保存到文件，持有文件锁以免较早的快照覆盖较新的快照
+	unlock := lockCacheFile(g.cacheFile)
+	defer unlock()
+	cacheData := make(map[string]bean.ResolvedLocation)
+	for k, v := range g.locationCache.Items() {
+		cacheData[k] = v.Object.(bean.ResolvedLocation)
+	}
+
+	data, err := json.Marshal(cacheData)
+	if err == nil {
+		writeCacheFile(g.cacheFile, data)
+	}
+}
+
+// bestAmapGeocode 选择第一个带有效区域编码的结果，高德已按匹配程度排序
+func bestAmapGeocode(geocodes []bean.AmapGeocode) (bean.AmapGeocode, bool) {
+	for _, geocode := range geocodes {
+		if adcode := string(geocode.Adcode); len(adcode) == 6 && isDigits(adcode) {
+			return geocode, true
+		}
+	}
+	return bean.AmapGeocode{}, false
+}
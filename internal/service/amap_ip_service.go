@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// defaultAmapIPURL 高德IP定位接口的默认地址
+const defaultAmapIPURL = "https://restapi.amap.com/v3/ip"
+
+// IPLocator IP定位服务接口，将客户端IP解析为所在城市
+type IPLocator interface {
+	Locate(ctx context.Context, ip string) (*bean.AdministrativeArea, error)
+}
+
+// amapIPLocator 基于高德IP定位接口的IP定位服务实现
+type amapIPLocator struct {
+	apiKey          string
+	baseURL         string
+	districtService DistrictService
+	ipCache         *cache.Cache
+}
+
+// NewAmapIPLocator 创建新的高德IP定位服务，只支持中国境内的IPv4地址，结果在内存中缓存。
+// baseURL为空时使用官方接口
+func NewAmapIPLocator(apiKey, baseURL string, districtService DistrictService) IPLocator {
+	if baseURL == "" {
+		baseURL = defaultAmapIPURL
+	}
+	return &amapIPLocator{
+		apiKey:          apiKey,
+		baseURL:         strings.TrimRight(baseURL, "/"),
+		districtService: districtService,
+		ipCache:         cache.New(24*time.Hour, 1*time.Hour),
+	}
+}
+
+// Locate 将IP解析为所在城市，坐标为城市范围的中心（GCJ-02）。
+// 局域网、回环地址以及高德无法定位的IP返回*bean.LocationNotFoundError，前者不请求高德接口
+func (l *amapIPLocator) Locate(ctx context.Context, ip string) (*bean.AdministrativeArea, error) {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return nil, &bean.LocationNotFoundError{Location: ip}
+	}
+	// 高德IP定位只支持IPv4
+	if addr.To4() == nil {
+		return nil, &bean.LocationNotFoundError{Location: ip}
+	}
+
+	key := addr.String()
+	if cached, found := l.ipCache.Get(key); found {
+		area := cached.(bean.AdministrativeArea)
+		logging.Debugf(ctx, amapLogger, "命中IP定位缓存: %s -> %s", key, area.Adcode)
+		return &area, nil
+	}
+
+	params := url.Values{}
+	params.Add("key", l.apiKey)
+	params.Add("ip", key)
+	params.Add("output", "JSON")
+
+	logging.Infof(ctx, amapLogger, "请求高德IP定位接口: ip=%s", key)
+	resp, err := httpGet(ctx, l.baseURL+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ipResp bean.AmapIPResponse
+	if err := json.Unmarshal(body, &ipResp); err != nil {
+		return nil, err
+	}
+
+	if ipResp.Status != "1" {
+		return nil, fmt.Errorf("API返回错误: %s", ipResp.Info)
+	}
+
+	adcode := string(ipResp.Adcode)
+	if len(adcode) != 6 || !isDigits(adcode) {
+		return nil, &bean.LocationNotFoundError{Location: ip}
+	}
+
+	area := bean.AdministrativeArea{
+		Country:  "中国",
+		Province: string(ipResp.Province),
+		City:     string(ipResp.City),
+		Adcode:   adcode,
+		CRS:      bean.CRSGCJ02,
+	}
+	// 城市编码取自内置行政区划表，直辖市与逆地理编码一致不填city
+	if district, found := l.districtService.Get(adcode); found {
+		area.Citycode = district.Citycode
+		if district.Level == bean.DistrictLevelProvince {
+			area.City = ""
+		}
+	}
+	area.Latitude, area.Longitude = rectangleCenter(string(ipResp.Rectangle))
+	logging.Infof(ctx, amapLogger, "IP定位: %s -> %s (%s%s)", key, area.Adcode, area.Province, area.City)
+
+	l.ipCache.Set(key, area, cache.DefaultExpiration)
+	return &area, nil
+}
+
+// rectangleCenter 计算高德矩形范围"经度,纬度;经度,纬度"的中心，返回纬度和经度，无法解析时返回0
+func rectangleCenter(rectangle string) (float64, float64) {
+	southWest, northEast, found := strings.Cut(rectangle, ";")
+	if !found {
+		return 0, 0
+	}
+
+	values := strings.Split(southWest+","+northEast, ",")
+	if len(values) != 4 {
+		return 0, 0
+	}
+	var coords [4]float64
+	for i, value := range values {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, 0
+		}
+		coords[i] = f
+	}
+
+	lat := (coords[1] + coords[3]) / 2
+	lon := (coords[0] + coords[2]) / 2
+	return math.Round(lat*1e6) / 1e6, math.Round(lon*1e6) / 1e6
+}
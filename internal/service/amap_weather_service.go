@@ -1,110 +1,153 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/patrickmn/go-cache"
 	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
 )
 
-// AmapWeatherService 高德地图天气服务接口
-type AmapWeatherService interface {
-	GetHourlyWeather(location string) (*bean.WeatherResponse, error)
+// amapLogger 高德地图服务的日志名称
+const amapLogger = "amap"
+
+// amapProvider 高德地图数据源名称
+const amapProvider = "amap"
+
+// amapTimezone 高德天气数据使用的北京时间
+var amapTimezone = time.FixedZone("CST", 8*60*60)
+
+func init() {
+	RegisterProvider(amapProvider, func(cfg ProviderConfig) (Provider, error) {
+		apiKey := cfg.Getenv("AMAP_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("未设置AMAP_API_KEY环境变量")
+		}
+		return NewAmapWeatherService(apiKey, cfg.DistrictService, cfg.Geocoder), nil
+	})
 }
 
 // amapWeatherService 高德地图天气服务实现
 type amapWeatherService struct {
-	apiKey        string
-	baseURL       string
-	locationCache *cache.Cache
-	cacheDir      string
-	cacheFile     string
+	apiKey          string
+	baseURL         string
+	districtService DistrictService
+	geocoder        AmapGeocoder
 }
 
-// NewAmapWeatherService 创建新的高德地图天气服务
-func NewAmapWeatherService(apiKey string) AmapWeatherService {
-	// 创建缓存目录
-	homeDir, _ := os.UserHomeDir()
-	cacheDir := filepath.Join(homeDir, ".cache", "amap_weather")
-	cacheFile := filepath.Join(cacheDir, "location_cache.json")
-
-	// 确保缓存目录存在
-	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
-		os.MkdirAll(cacheDir, 0755)
+// NewAmapWeatherService 创建新的高德地图天气服务，geocoder为空时创建新的地理编码服务
+func NewAmapWeatherService(apiKey string, districtService DistrictService, geocoder AmapGeocoder) Provider {
+	if geocoder == nil {
+		geocoder = NewAmapGeocoder(apiKey)
 	}
-
-	// 初始化缓存
-	c := cache.New(24*time.Hour, 1*time.Hour)
-
-	// 尝试从文件加载缓存
-	if _, err := os.Stat(cacheFile); err == nil {
-		data, err := os.ReadFile(cacheFile)
-		if err == nil {
-			var cacheData map[string]string
-			if err := json.Unmarshal(data, &cacheData); err == nil {
-				for k, v := range cacheData {
-					c.Set(k, v, cache.NoExpiration)
-				}
-			}
-		}
+	return &amapWeatherService{
+		apiKey:          apiKey,
+		baseURL:         "https://restapi.amap.com/v3/weather/weatherInfo",
+		districtService: districtService,
+		geocoder:        geocoder,
 	}
+}
 
-	return &amapWeatherService{
-		apiKey:        apiKey,
-		baseURL:       "https://restapi.amap.com/v3/weather/weatherInfo",
-		locationCache: c,
-		cacheDir:      cacheDir,
-		cacheFile:     cacheFile,
+// Name 返回数据源名称
+func (s *amapWeatherService) Name() string {
+	return amapProvider
+}
+
+// Capabilities 返回数据源能力，高德只提供中国范围的逐日预报，逐小时预报由逐日预报推算
+func (s *amapWeatherService) Capabilities() bean.ProviderCapabilities {
+	return bean.ProviderCapabilities{
+		HourlyData: false,
+		DailyData:  true,
+		Coverage:   bean.ProviderCoverageChina,
+		Adcodes:    true,
 	}
 }
 
 // GetHourlyWeather 获取每小时天气预报
-func (s *amapWeatherService) GetHourlyWeather(location string) (*bean.WeatherResponse, error) {
-	// 尝试从缓存获取城市编码
-	cityCode, found := s.getCachedCityCode(location)
+func (s *amapWeatherService) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	cityCode, found, err := s.resolveCityCode(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	if found && !s.districtService.ValidAdcode(cityCode) {
+		// 不存在的区域编码直接返回，不消耗高德配额
+		return nil, &bean.LocationNotFoundError{Location: location}
+	}
 	if !found {
-		// 如果缓存中没有，则使用输入的位置作为城市编码
-		// 高德地图API支持城市名称、区域编码等多种方式
-		cityCode = location
-		// 缓存城市编码
-		s.cacheCityCode(location, cityCode)
+		// 同名区划由调用方确认，不交给高德随意选择
+		if candidates := s.districtService.Match(location); len(candidates) > 1 {
+			return nil, &bean.AmbiguousLocationError{Location: location, Candidates: candidates}
+		}
+
+		// 其余输入（详细地址、英文名称等）通过地理编码解析为区域编码
+		resolved, err := s.geocoder.Geocode(ctx, location)
+		if err != nil {
+			logging.Errorf(ctx, amapLogger, "解析地点失败 %s: %v", location, err)
+			return nil, fmt.Errorf("解析地点失败: %w", err)
+		}
+		cityCode = resolved.Adcode
 	}
 
 	// 获取实况天气
-	liveWeather, err := s.getLiveWeather(cityCode)
+	liveWeather, err := s.getLiveWeather(ctx, cityCode)
 	if err != nil {
+		logging.Errorf(ctx, amapLogger, "获取实况天气失败 %s: %v", cityCode, err)
 		return nil, fmt.Errorf("获取实况天气失败: %w", err)
 	}
+	// 高德对无法识别的地点返回空的实况列表
+	if len(liveWeather.Lives) == 0 {
+		return nil, &bean.LocationNotFoundError{Location: location}
+	}
 
 	// 获取天气预报
-	forecastWeather, err := s.getForecastWeather(cityCode)
+	forecastWeather, err := s.getForecastWeather(ctx, cityCode)
 	if err != nil {
+		logging.Errorf(ctx, amapLogger, "获取天气预报失败 %s: %v", cityCode, err)
 		return nil, fmt.Errorf("获取天气预报失败: %w", err)
 	}
 
 	// 构建响应
 	response := s.buildWeatherResponse(liveWeather, forecastWeather)
+	response.Provider = amapProvider
 	return response, nil
 }
 
+// resolveCityCode 将坐标或能在行政区划表中唯一确定的地点解析为区域编码，
+// 坐标（WGS-84）转换为GCJ-02后通过逆地理编码解析，其他无法直接确定的地点返回found为false
+func (s *amapWeatherService) resolveCityCode(ctx context.Context, location string) (string, bool, error) {
+	if lat, lon, ok := parseCoordinates(location); ok {
+		lat, lon = ConvertCoordinates(lat, lon, bean.CRSWGS84, bean.CRSGCJ02)
+		area, err := s.geocoder.ReverseGeocode(ctx, lat, lon)
+		if err != nil {
+			logging.Errorf(ctx, amapLogger, "逆地理编码失败 %s: %v", location, err)
+			return "", false, fmt.Errorf("解析坐标失败: %w", err)
+		}
+		return area.Adcode, true, nil
+	}
+
+	// 区域编码或能在行政区划表中唯一确定的地点直接使用其区域编码
+	cityCode, found := s.districtService.ResolveAdcode(location)
+	return cityCode, found, nil
+}
+
 // getLiveWeather 获取实况天气
-func (s *amapWeatherService) getLiveWeather(cityCode string) (*bean.AmapWeatherResponse, error) {
+func (s *amapWeatherService) getLiveWeather(ctx context.Context, cityCode string) (*bean.AmapWeatherResponse, error) {
 	params := url.Values{}
 	params.Add("key", s.apiKey)
 	params.Add("city", cityCode)
 	params.Add("extensions", "base")
 	params.Add("output", "JSON")
 
-	resp, err := http.Get(s.baseURL + "?" + params.Encode())
+	logging.Infof(ctx, amapLogger, "请求高德天气接口: city=%s extensions=base", cityCode)
+	resp, err := httpGet(ctx, s.baseURL+"?"+params.Encode())
 	if err != nil {
 		return nil, err
 	}
@@ -132,14 +175,15 @@ func (s *amapWeatherService) getLiveWeather(cityCode string) (*bean.AmapWeatherR
 }
 
 // getForecastWeather 获取天气预报
-func (s *amapWeatherService) getForecastWeather(cityCode string) (*bean.AmapWeatherResponse, error) {
+func (s *amapWeatherService) getForecastWeather(ctx context.Context, cityCode string) (*bean.AmapWeatherResponse, error) {
 	params := url.Values{}
 	params.Add("key", s.apiKey)
 	params.Add("city", cityCode)
 	params.Add("extensions", "all")
 	params.Add("output", "JSON")
 
-	resp, err := http.Get(s.baseURL + "?" + params.Encode())
+	logging.Infof(ctx, amapLogger, "请求高德天气接口: city=%s extensions=all", cityCode)
+	resp, err := httpGet(ctx, s.baseURL+"?"+params.Encode())
 	if err != nil {
 		return nil, err
 	}
@@ -195,21 +239,22 @@ func (s *amapWeatherService) buildWeatherResponse(liveWeather, forecastWeather *
 	// 这里我们将按天的预报转换为模拟的每小时预报
 	hourlyForecasts := make([]bean.HourlyForecast, 0)
 
-	// 获取当前小时
-	now := time.Now()
-	currentHour := now.Hour()
+	// 按北京时间计算未来12小时的预报时刻，过了午夜的小时使用明天的预报
+	now := time.Now().In(amapTimezone).Truncate(time.Hour)
+	casts := make(map[string]bean.AmapWeatherCast, len(forecast.Casts))
+	for _, cast := range forecast.Casts {
+		casts[cast.Date] = cast
+	}
 
-	// 为今天和明天的每个小时创建预报
-	for i := 0; i < 24; i++ {
-		hour := (currentHour + i) % 24
-		day := i / 24 // 0表示今天，1表示明天
+	for i := 0; i < 12; i++ {
+		dateTime := now.Add(time.Duration(i+1) * time.Hour)
+		hour := dateTime.Hour()
 
-		if day >= len(forecast.Casts) {
+		cast, found := casts[dateTime.Format(time.DateOnly)]
+		if !found {
 			break
 		}
 
-		cast := forecast.Casts[day]
-
 		// 根据小时确定使用白天还是晚上的数据
 		var weatherText, tempStr string
 		var precipitationType, precipitationIntensity string
@@ -274,6 +319,7 @@ func (s *amapWeatherService) buildWeatherResponse(liveWeather, forecastWeather *
 
 		hourlyForecast := bean.HourlyForecast{
 			RelativeTime: relativeTime,
+			DateTime:     dateTime.Format(time.RFC3339),
 			Temperature: bean.Temperature{
 				Value: temp,
 				Unit:  "C",
@@ -285,11 +331,6 @@ func (s *amapWeatherService) buildWeatherResponse(liveWeather, forecastWeather *
 		}
 
 		hourlyForecasts = append(hourlyForecasts, hourlyForecast)
-
-		// 只保留12小时的预报
-		if len(hourlyForecasts) >= 12 {
-			break
-		}
 	}
 
 	return &bean.WeatherResponse{
@@ -298,30 +339,24 @@ func (s *amapWeatherService) buildWeatherResponse(liveWeather, forecastWeather *
 		Country:           "中国",
 		CurrentConditions: currentConditions,
 		HourlyForecast:    hourlyForecasts,
+		DailyForecast:     s.formatDailyForecast(forecast.Casts),
+		ForecastTime:      forecast.Reporttime,
 	}
 }
 
-// getCachedCityCode 从缓存获取城市编码
-func (s *amapWeatherService) getCachedCityCode(location string) (string, bool) {
-	if cityCode, found := s.locationCache.Get(location); found {
-		return cityCode.(string), true
-	}
-	return "", false
-}
-
-// cacheCityCode 缓存城市编码
-func (s *amapWeatherService) cacheCityCode(location, cityCode string) {
-	s.locationCache.Set(location, cityCode, cache.DefaultExpiration)
-
-	// 将缓存保存到文件
-	cacheData := make(map[string]string)
-	items := s.locationCache.Items()
-	for k, v := range items {
-		cacheData[k] = v.Object.(string)
-	}
-
-	data, err := json.Marshal(cacheData)
-	if err == nil {
-		os.WriteFile(s.cacheFile, data, 0644)
+// formatDailyForecast 格式化逐日预报，高德只给出白天和夜间的气温，不提供降水量和湿度
+func (s *amapWeatherService) formatDailyForecast(casts []bean.AmapWeatherCast) []bean.DailyForecast {
+	result := make([]bean.DailyForecast, 0, len(casts))
+	for _, cast := range casts {
+		dayTemp, _ := strconv.ParseFloat(cast.DayTemp, 64)
+		nightTemp, _ := strconv.ParseFloat(cast.NightTemp, 64)
+		result = append(result, bean.DailyForecast{
+			Date:             cast.Date,
+			TempMax:          bean.Temperature{Value: math.Max(dayTemp, nightTemp), Unit: "C"},
+			TempMin:          bean.Temperature{Value: math.Min(dayTemp, nightTemp), Unit: "C"},
+			WeatherTextDay:   cast.DayWeather,
+			WeatherTextNight: cast.NightWeather,
+		})
 	}
+	return result
 }
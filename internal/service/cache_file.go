@@ -0,0 +1,40 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheFileLocks 各缓存文件的锁，同一文件可能被多个服务实例同时更新
+var cacheFileLocks sync.Map
+
+// lockCacheFile 锁定缓存文件，返回解锁函数。读取、修改和写回缓存文件期间应持有该锁，
+// 否则并发的更新会互相覆盖
+func lockCacheFile(path string) func() {
+	mu, _ := cacheFileLocks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// writeCacheFile 原子地写入缓存文件：先写入同目录的临时文件再重命名，
+// 进程中途退出或并发读取时不会看到写了一半的文件
+func writeCacheFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
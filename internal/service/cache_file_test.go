@@ -0,0 +1,56 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentCacheFileUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	// 模拟多个请求同时读取、更新和写回同一个缓存文件
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			unlock := lockCacheFile(path)
+			defer unlock()
+
+			cacheData := make(map[string]int)
+			if data, err := os.ReadFile(path); err == nil {
+				if err := json.Unmarshal(data, &cacheData); err != nil {
+					t.Errorf("read a partial cache file: %v", err)
+				}
+			}
+			cacheData[fmt.Sprint(i)] = i
+			data, _ := json.Marshal(cacheData)
+			if err := writeCacheFile(path, data); err != nil {
+				t.Errorf("writeCacheFile() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	cacheData := make(map[string]int)
+	if err := json.Unmarshal(data, &cacheData); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(cacheData) != 20 {
+		t.Errorf("cache file has %d entries, want 20", len(cacheData))
+	}
+
+	// 临时文件已清理
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("cache directory has %d files, want only the cache file", len(entries))
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"math"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// GCJ-02偏移算法使用的克拉索夫斯基椭球参数
+const (
+	krasovskyA  = 6378245.0
+	krasovskyEE = 0.00669342162296594323
+)
+
+// bd09XPi BD-09与GCJ-02互转使用的常数
+const bd09XPi = math.Pi * 3000.0 / 180.0
+
+// ConvertCoordinates 在WGS-84、GCJ-02和BD-09之间转换坐标，坐标系名称需经bean.ParseCRS规范化。
+// 中国境外的坐标没有偏移，WGS-84与GCJ-02相同
+func ConvertCoordinates(lat, lon float64, from, to string) (float64, float64) {
+	if from == to {
+		return lat, lon
+	}
+
+	// 先统一转换为GCJ-02，再转换为目标坐标系
+	switch from {
+	case bean.CRSWGS84:
+		lat, lon = wgs84ToGCJ02(lat, lon)
+	case bean.CRSBD09:
+		lat, lon = bd09ToGCJ02(lat, lon)
+	}
+	switch to {
+	case bean.CRSWGS84:
+		return gcj02ToWGS84(lat, lon)
+	case bean.CRSBD09:
+		return gcj02ToBD09(lat, lon)
+	}
+	return lat, lon
+}
+
+// outOfChina 坐标是否在中国境外，境外不做GCJ-02偏移
+func outOfChina(lat, lon float64) bool {
+	return lon < 72.004 || lon > 137.8347 || lat < 0.8293 || lat > 55.8271
+}
+
+// wgs84ToGCJ02 WGS-84转GCJ-02
+func wgs84ToGCJ02(lat, lon float64) (float64, float64) {
+	if outOfChina(lat, lon) {
+		return lat, lon
+	}
+	dLat, dLon := gcj02Offset(lat, lon)
+	return lat + dLat, lon + dLon
+}
+
+// gcj02ToWGS84 GCJ-02转WGS-84。偏移没有解析逆运算，迭代修正直到误差小于1e-9度（约0.1毫米）
+func gcj02ToWGS84(lat, lon float64) (float64, float64) {
+	if outOfChina(lat, lon) {
+		return lat, lon
+	}
+	wgsLat, wgsLon := lat, lon
+	for i := 0; i < 30; i++ {
+		gcjLat, gcjLon := wgs84ToGCJ02(wgsLat, wgsLon)
+		errLat, errLon := gcjLat-lat, gcjLon-lon
+		wgsLat -= errLat
+		wgsLon -= errLon
+		if math.Abs(errLat) < 1e-9 && math.Abs(errLon) < 1e-9 {
+			break
+		}
+	}
+	return wgsLat, wgsLon
+}
+
+// gcj02ToBD09 GCJ-02转BD-09
+func gcj02ToBD09(lat, lon float64) (float64, float64) {
+	z := math.Sqrt(lon*lon+lat*lat) + 0.00002*math.Sin(lat*bd09XPi)
+	theta := math.Atan2(lat, lon) + 0.000003*math.Cos(lon*bd09XPi)
+	return z*math.Sin(theta) + 0.006, z*math.Cos(theta) + 0.0065
+}
+
+// bd09ToGCJ02 BD-09转GCJ-02
+func bd09ToGCJ02(lat, lon float64) (float64, float64) {
+	x, y := lon-0.0065, lat-0.006
+	z := math.Sqrt(x*x+y*y) - 0.00002*math.Sin(y*bd09XPi)
+	theta := math.Atan2(y, x) - 0.000003*math.Cos(x*bd09XPi)
+	return z * math.Sin(theta), z * math.Cos(theta)
+}
+
+// gcj02Offset 计算WGS-84坐标在GCJ-02中的偏移量，单位为度
+func gcj02Offset(lat, lon float64) (float64, float64) {
+	x, y := lon-105.0, lat-35.0
+	dLat := -100.0 + 2.0*x + 3.0*y + 0.2*y*y + 0.1*x*y + 0.2*math.Sqrt(math.Abs(x))
+	dLat += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	dLat += (20.0*math.Sin(y*math.Pi) + 40.0*math.Sin(y/3.0*math.Pi)) * 2.0 / 3.0
+	dLat += (160.0*math.Sin(y/12.0*math.Pi) + 320*math.Sin(y*math.Pi/30.0)) * 2.0 / 3.0
+	dLon := 300.0 + x + 2.0*y + 0.1*x*x + 0.1*x*y + 0.1*math.Sqrt(math.Abs(x))
+	dLon += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	dLon += (20.0*math.Sin(x*math.Pi) + 40.0*math.Sin(x/3.0*math.Pi)) * 2.0 / 3.0
+	dLon += (150.0*math.Sin(x/12.0*math.Pi) + 300.0*math.Sin(x/30.0*math.Pi)) * 2.0 / 3.0
+
+	radLat := lat / 180.0 * math.Pi
+	magic := math.Sin(radLat)
+	magic = 1 - krasovskyEE*magic*magic
+	sqrtMagic := math.Sqrt(magic)
+	dLat = (dLat * 180.0) / ((krasovskyA * (1 - krasovskyEE)) / (magic * sqrtMagic) * math.Pi)
+	dLon = (dLon * 180.0) / (krasovskyA / sqrtMagic * math.Cos(radLat) * math.Pi)
+	return dLat, dLon
+}
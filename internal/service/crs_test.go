@@ -0,0 +1,83 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// closeTo 判断两个坐标在容差（度）内是否相同
+func closeTo(lat, lon, wantLat, wantLon, tolerance float64) bool {
+	return math.Abs(lat-wantLat) <= tolerance && math.Abs(lon-wantLon) <= tolerance
+}
+
+func TestConvertCoordinatesReference(t *testing.T) {
+	// 参考值取自常用的coordtransform库，输入均为(39.915, 116.404)。
+	// 该库的GCJ-02转WGS-84只做一次近似修正，与迭代求逆相差约1e-5度以内
+	tests := []struct {
+		from, to         string
+		wantLat, wantLon float64
+		tolerance        float64
+	}{
+		{bean.CRSWGS84, bean.CRSGCJ02, 39.91640428150164, 116.41024449916938, 1e-9},
+		{bean.CRSGCJ02, bean.CRSBD09, 39.92133699351021, 116.41036949371029, 1e-9},
+		{bean.CRSBD09, bean.CRSGCJ02, 39.90865673957631, 116.39762729119315, 1e-9},
+		{bean.CRSGCJ02, bean.CRSWGS84, 39.91359571849836, 116.39775550083061, 1e-5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			lat, lon := ConvertCoordinates(39.915, 116.404, tt.from, tt.to)
+			if !closeTo(lat, lon, tt.wantLat, tt.wantLon, tt.tolerance) {
+				t.Errorf("ConvertCoordinates() = %.10f, %.10f, want %.10f, %.10f", lat, lon, tt.wantLat, tt.wantLon)
+			}
+		})
+	}
+}
+
+func TestConvertCoordinatesRoundTrip(t *testing.T) {
+	points := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"北京", 39.9042, 116.4074},
+		{"上海", 31.2304, 121.4737},
+		{"乌鲁木齐", 43.8256, 87.6168},
+		{"三亚", 18.2528, 109.5119},
+	}
+	crsNames := []string{bean.CRSWGS84, bean.CRSGCJ02, bean.CRSBD09}
+	for _, p := range points {
+		for _, from := range crsNames {
+			for _, to := range crsNames {
+				lat, lon := ConvertCoordinates(p.lat, p.lon, from, to)
+				backLat, backLon := ConvertCoordinates(lat, lon, to, from)
+				// BD-09的逆运算是近似的，往返误差在1e-5度（约1米）以内
+				if !closeTo(backLat, backLon, p.lat, p.lon, 1e-5) {
+					t.Errorf("%s %s->%s->%s = %.8f, %.8f, want %.8f, %.8f", p.name, from, to, from, backLat, backLon, p.lat, p.lon)
+				}
+				// 中国境内不同坐标系之间有明显偏移
+				if from != to && closeTo(lat, lon, p.lat, p.lon, 1e-4) {
+					t.Errorf("%s %s->%s = %.8f, %.8f, want an offset", p.name, from, to, lat, lon)
+				}
+			}
+		}
+	}
+}
+
+func TestConvertCoordinatesOutOfChina(t *testing.T) {
+	points := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"New York", 40.7128, -74.0060},
+		{"London", 51.5074, -0.1278},
+		{"Sydney", -33.8688, 151.2093},
+	}
+	for _, p := range points {
+		for _, crs := range [][2]string{{bean.CRSWGS84, bean.CRSGCJ02}, {bean.CRSGCJ02, bean.CRSWGS84}} {
+			if lat, lon := ConvertCoordinates(p.lat, p.lon, crs[0], crs[1]); lat != p.lat || lon != p.lon {
+				t.Errorf("%s %s->%s = %v, %v, want unchanged", p.name, crs[0], crs[1], lat, lon)
+			}
+		}
+	}
+}
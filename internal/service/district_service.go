@@ -0,0 +1,420 @@
+package service
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// districtsCSV 内置的行政区划表，包含全部省级、地级和县级行政区划，以及各区划的城市编码
+//
+//go:embed data/districts.csv
+var districtsCSV []byte
+
+// districtSuffixes 行政区划名称后缀，按长度从长到短排列
+var districtSuffixes = []string{"特别行政区", "自治区", "自治州", "自治县", "地区", "新区", "林区", "省", "市", "区", "县", "盟", "旗"}
+
+// adcodePattern 区域编码格式
+var adcodePattern = regexp.MustCompile(`^\d{6}$`)
+
+// adcodeSuffixPattern 以"名称 (区域编码)"形式给出的地点
+var adcodeSuffixPattern = regexp.MustCompile(`[(（]\s*(\d{6})\s*[)）]\s*$`)
+
+// DistrictService 行政区划查询服务接口
+type DistrictService interface {
+	Search(query string, limit int) []bean.District
+	Get(adcode string) (bean.District, bool)
+	ResolveAdcode(location string) (string, bool)
+	Match(location string) []bean.District
+	ValidAdcode(adcode string) bool
+}
+
+// districtEntry 行政区划索引项
+type districtEntry struct {
+	district bean.District
+	base     string // 去掉后缀的名称，例如：朝阳
+	compact  string // 去掉后缀的各级名称拼接，例如：北京朝阳
+	initials string // 拼音首字母，例如：cy
+}
+
+// districtService 行政区划查询服务实现
+type districtService struct {
+	entries  []*districtEntry
+	byAdcode map[string]*districtEntry
+}
+
+// NewDistrictService 创建新的行政区划查询服务
+func NewDistrictService() DistrictService {
+	s := &districtService{
+		byAdcode: make(map[string]*districtEntry),
+	}
+	s.load(districtsCSV)
+	return s
+}
+
+// load 加载行政区划表，每行格式为 adcode,name,pinyin,citycode
+func (s *districtService) load(data []byte) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return
+	}
+
+	for i, record := range records {
+		if i == 0 || len(record) < 2 || !adcodePattern.MatchString(record[0]) {
+			continue
+		}
+
+		entry := &districtEntry{
+			district: bean.District{
+				Adcode: record[0],
+				Name:   record[1],
+				Level:  districtLevel(record[0]),
+			},
+		}
+
+		if len(record) > 2 && record[2] != "" {
+			syllables := strings.Fields(record[2])
+			entry.district.Pinyin = strings.Join(syllables, "")
+			for _, syllable := range syllables {
+				entry.initials += syllable[:1]
+			}
+			// 拼音只覆盖不含后缀的名称，按音节数截取
+			entry.base = truncateRunes(record[1], len(syllables))
+		} else {
+			entry.base = stripDistrictSuffix(record[1])
+		}
+		if len(record) > 3 {
+			entry.district.Citycode = record[3]
+		}
+
+		if _, exists := s.byAdcode[entry.district.Adcode]; !exists {
+			s.entries = append(s.entries, entry)
+		}
+		s.byAdcode[entry.district.Adcode] = entry
+	}
+
+	// 所有行加载后再拼接上级区划名称
+	for _, entry := range s.entries {
+		fullName, compact := entry.district.Name, entry.base
+		for _, parent := range s.parents(entry.district.Adcode) {
+			fullName = parent.district.Name + fullName
+			compact = parent.base + compact
+		}
+		entry.district.FullName = fullName
+		entry.compact = compact
+	}
+}
+
+// parents 返回区划的上级区划，由近到远
+func (s *districtService) parents(adcode string) []*districtEntry {
+	var result []*districtEntry
+	level := districtLevel(adcode)
+	// 特别行政区的区县按编码推出的地级区划是省级区划本身，例如810001对应810000
+	if level == bean.DistrictLevelDistrict {
+		if city, found := s.byAdcode[adcode[:4]+"00"]; found && city.district.Level == bean.DistrictLevelCity {
+			result = append(result, city)
+		}
+	}
+	if level != bean.DistrictLevelProvince {
+		if province, found := s.byAdcode[adcode[:2]+"0000"]; found {
+			result = append(result, province)
+		}
+	}
+	return result
+}
+
+// Get 按区域编码查询行政区划
+func (s *districtService) Get(adcode string) (bean.District, bool) {
+	entry, found := s.byAdcode[adcode]
+	if !found {
+		return bean.District{}, false
+	}
+	return entry.district, true
+}
+
+// Search 按名称前缀、拼音、拼音首字母、区域编码或城市编码搜索行政区划，
+// 没有结果时按编辑距离容错匹配，limit不大于0时返回全部结果
+func (s *districtService) Search(query string, limit int) []bean.District {
+	q := normalizeDistrictQuery(query)
+	if q == "" {
+		return nil
+	}
+
+	type match struct {
+		entry *districtEntry
+		score int
+	}
+	var matches []match
+	for _, entry := range s.entries {
+		if score := entry.matchScore(q); score >= 0 {
+			matches = append(matches, match{entry, score})
+		}
+	}
+	// 精确和前缀匹配都没有结果时才容错，避免错别字结果混入补全列表
+	if len(matches) == 0 {
+		for _, entry := range s.entries {
+			if score := entry.fuzzyScore(q); score >= 0 {
+				matches = append(matches, match{entry, score})
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		li, lj := districtLevelRank(matches[i].entry.district.Level), districtLevelRank(matches[j].entry.district.Level)
+		if li != lj {
+			return li < lj
+		}
+		return matches[i].entry.district.Adcode < matches[j].entry.district.Adcode
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]bean.District, len(matches))
+	for i, m := range matches {
+		result[i] = m.entry.district
+	}
+	return result
+}
+
+// ResolveAdcode 将地点解析为区域编码，支持区域编码、"名称 (区域编码)"形式，
+// 以及能唯一确定一个区划的名称
+func (s *districtService) ResolveAdcode(location string) (string, bool) {
+	if adcode, found := explicitAdcode(location); found {
+		return adcode, true
+	}
+	if candidates := s.Match(location); len(candidates) == 1 {
+		return candidates[0].Adcode, true
+	}
+	return "", false
+}
+
+// Match 返回名称与地点对应的所有区划，完整名称精确匹配优先于去掉后缀的名称匹配。
+// 同名的上级区划会被省略，例如"吉林"只返回吉林市而不返回吉林省；
+// 与所属地级市同名的县则让位于地级市，例如"承德"只返回承德市而不返回承德县
+func (s *districtService) Match(location string) []bean.District {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return nil
+	}
+
+	var exact, loose []*districtEntry
+	stripped := stripDistrictSuffix(location)
+	for _, entry := range s.entries {
+		switch {
+		case entry.district.Name == location || entry.district.FullName == location:
+			exact = append(exact, entry)
+		case entry.base == stripped || entry.compact == stripped:
+			loose = append(loose, entry)
+		}
+	}
+
+	entries := exact
+	if len(entries) == 0 {
+		entries = loose
+	}
+
+	matched := make(map[string]bool)
+	for _, entry := range entries {
+		matched[entry.district.Adcode] = true
+	}
+	omitted := make(map[string]bool)
+	for _, entry := range entries {
+		for _, parent := range s.parents(entry.district.Adcode) {
+			if !matched[parent.district.Adcode] {
+				continue
+			}
+			if parent.district.Level == bean.DistrictLevelCity {
+				omitted[entry.district.Adcode] = true
+			} else {
+				omitted[parent.district.Adcode] = true
+			}
+		}
+	}
+
+	var result []bean.District
+	for _, entry := range entries {
+		if !omitted[entry.district.Adcode] {
+			result = append(result, entry.district)
+		}
+	}
+	return result
+}
+
+// ValidAdcode 判断区域编码是否有效，内置表包含全部县级及以上区划，不在表中的编码视为不存在
+func (s *districtService) ValidAdcode(adcode string) bool {
+	_, found := s.byAdcode[adcode]
+	return found
+}
+
+// matchScore 计算查询与区划的匹配程度，越小越匹配，不匹配返回-1
+func (e *districtEntry) matchScore(q string) int {
+	d := e.district
+	if isDigits(q) {
+		switch {
+		case d.Adcode == q:
+			return 0
+		case d.Citycode == q:
+			return 1
+		case strings.HasPrefix(d.Adcode, q):
+			return 5
+		}
+		return -1
+	}
+
+	if isASCII(q) {
+		switch {
+		case d.Pinyin == q:
+			return 3
+		case strings.HasPrefix(d.Pinyin, q):
+			return 4
+		case len(q) > 1 && strings.HasPrefix(e.initials, q):
+			return 5
+		}
+		return -1
+	}
+
+	switch {
+	case d.Name == q || d.FullName == q || e.base == stripDistrictSuffix(q):
+		return 0
+	case strings.HasPrefix(d.Name, q) || strings.HasPrefix(e.base, q):
+		return 1
+	case strings.HasPrefix(d.FullName, q) || strings.HasPrefix(e.compact, q):
+		return 2
+	}
+	return -1
+}
+
+// fuzzyScore 按编辑距离计算查询与区划的匹配程度，中文与名称比较，拼音与全拼比较，
+// 不匹配返回-1。得分排在所有精确和前缀匹配之后
+func (e *districtEntry) fuzzyScore(q string) int {
+	if isDigits(q) {
+		return -1
+	}
+
+	var candidates []string
+	var maxDistance int
+	if isASCII(q) {
+		// 拼音较长时才容错，避免短拼音匹配到大量区划
+		candidates = []string{e.district.Pinyin}
+		maxDistance = len(q) / 4
+	} else {
+		q = stripDistrictSuffix(q)
+		candidates = []string{e.base, e.compact}
+		maxDistance = 1 + utf8.RuneCountInString(q)/6
+	}
+
+	best := -1
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if d := editDistance(q, candidate); d <= maxDistance && (best < 0 || d < best) {
+			best = d
+		}
+	}
+	if best < 0 {
+		return -1
+	}
+	return 10 + best
+}
+
+// editDistance 计算两个字符串按字符的编辑距离
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// districtLevel 根据区域编码判断行政区划级别
+func districtLevel(adcode string) string {
+	switch {
+	case strings.HasSuffix(adcode, "0000"):
+		return bean.DistrictLevelProvince
+	case strings.HasSuffix(adcode, "00"):
+		return bean.DistrictLevelCity
+	default:
+		return bean.DistrictLevelDistrict
+	}
+}
+
+// districtLevelRank 行政区划级别的排序权重
+func districtLevelRank(level string) int {
+	switch level {
+	case bean.DistrictLevelProvince:
+		return 0
+	case bean.DistrictLevelCity:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// stripDistrictSuffix 去掉名称的行政区划后缀，去掉后不足两个字时保留原名
+func stripDistrictSuffix(name string) string {
+	for _, suffix := range districtSuffixes {
+		if base := strings.TrimSuffix(name, suffix); base != name && utf8.RuneCountInString(base) >= 2 {
+			return base
+		}
+	}
+	return name
+}
+
+// normalizeDistrictQuery 规范化查询字符串
+func normalizeDistrictQuery(query string) string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	return strings.NewReplacer(" ", "", "'", "", "’", "").Replace(query)
+}
+
+// truncateRunes 截取字符串的前n个字符
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if n >= len(runes) {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// isDigits 字符串是否全部为数字
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isASCII 字符串是否全部为ASCII字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
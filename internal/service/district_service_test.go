@@ -0,0 +1,143 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// adcodes 返回区划的区域编码
+func adcodes(districts []bean.District) []string {
+	result := make([]string, len(districts))
+	for i, d := range districts {
+		result[i] = d.Adcode
+	}
+	return result
+}
+
+// containsAdcode 判断区划列表中是否有指定区域编码
+func containsAdcode(districts []bean.District, adcode string) bool {
+	for _, d := range districts {
+		if d.Adcode == adcode {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDistrictTable(t *testing.T) {
+	s := NewDistrictService().(*districtService)
+	if got := len(s.entries); got != 3237 {
+		t.Errorf("district table has %d rows, want 3237", got)
+	}
+
+	levels := make(map[string]int)
+	for _, entry := range s.entries {
+		levels[entry.district.Level]++
+	}
+	if got := levels[bean.DistrictLevelDistrict]; got != 2870 {
+		t.Errorf("district table has %d county-level rows, want 2870", got)
+	}
+
+	// 港澳的区划同样在表中
+	for _, adcode := range []string{"810001", "810018", "820001", "820008"} {
+		if !s.ValidAdcode(adcode) {
+			t.Errorf("ValidAdcode(%q) = false, want true", adcode)
+		}
+	}
+	if s.ValidAdcode("999999") {
+		t.Error(`ValidAdcode("999999") = true, want false`)
+	}
+}
+
+func TestDistrictSearch(t *testing.T) {
+	s := NewDistrictService()
+	tests := []struct {
+		query string
+		want  string // 结果中应包含的区域编码
+		first bool   // 是否应排在第一位
+	}{
+		{"北京市", "110000", true},
+		{"北京", "110000", true},
+		{"110105", "110105", true},
+		{"010", "110000", false},
+		{"haidian", "110108", true},
+		{"hd", "110108", false},
+		{"chaoyang", "110105", false},
+		{"cy", "220104", false},
+		// 错别字和拼音拼错按编辑距离容错
+		{"北惊", "110000", true},
+		{"beijng", "110000", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := s.Search(tt.query, 10)
+			if !containsAdcode(got, tt.want) {
+				t.Fatalf("Search(%q) = %v, want %s", tt.query, adcodes(got), tt.want)
+			}
+			if tt.first && got[0].Adcode != tt.want {
+				t.Errorf("Search(%q)[0] = %s, want %s", tt.query, got[0].Adcode, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistrictMatch(t *testing.T) {
+	s := NewDistrictService()
+	tests := []struct {
+		location string
+		want     []string
+	}{
+		{"海淀", []string{"110108"}},
+		{"北京市朝阳区", []string{"110105"}},
+		// 同名的上级区划被省略
+		{"吉林", []string{"220200"}},
+		{"吉林省", []string{"220000"}},
+		// 与所属地级市同名的县让位于地级市
+		{"承德", []string{"130800"}},
+		{"承德县", []string{"130821"}},
+		{"朝阳", []string{"110105", "211300", "220104"}},
+		{"鼓楼", []string{"320106", "320302", "350102", "410204"}},
+		{"不存在的地方", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			matched := s.Match(tt.location)
+			if len(matched) != len(tt.want) {
+				t.Fatalf("Match(%q) = %v, want %v", tt.location, adcodes(matched), tt.want)
+			}
+			for _, adcode := range tt.want {
+				if !containsAdcode(matched, adcode) {
+					t.Errorf("Match(%q) = %v, want %v", tt.location, adcodes(matched), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveAdcode(t *testing.T) {
+	s := NewDistrictService()
+	tests := []struct {
+		location  string
+		want      string
+		wantFound bool
+	}{
+		{"110105", "110105", true},
+		{"北京市朝阳区 (110105)", "110105", true},
+		{"吉林省长春市朝阳区(220104)", "220104", true},
+		{"朝阳（220104）", "220104", true},
+		{" 朝阳区 ( 110105 ) ", "110105", true},
+		{"吉林", "220200", true},
+		{"承德", "130800", true},
+		{"朝阳", "", false},
+		{"北京市朝阳区 (1101)", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			got, found := s.ResolveAdcode(tt.location)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("ResolveAdcode(%q) = %q, %t, want %q, %t", tt.location, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
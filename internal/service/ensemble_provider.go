@@ -0,0 +1,482 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// ensembleLogger 集合预报的日志名称
+const ensembleLogger = "ensemble"
+
+// ensembleProvider 集合预报数据源名称
+const ensembleProvider = "ensemble"
+
+// 集合预报的合并方式
+const (
+	EnsembleMedian   = "median"
+	EnsembleWeighted = "weighted"
+)
+
+// defaultEnsembleTimeout 单个成员查询的默认超时，超时的成员不参与合并
+const defaultEnsembleTimeout = 10 * time.Second
+
+func init() {
+	RegisterProvider(ensembleProvider, func(cfg ProviderConfig) (Provider, error) {
+		names := strings.Split(cfg.Getenv("WEATHER_ENSEMBLE_PROVIDERS"), ",")
+		weights, err := parseEnsembleWeights(cfg.Getenv("WEATHER_ENSEMBLE_WEIGHTS"))
+		if err != nil {
+			return nil, err
+		}
+		var timeout time.Duration
+		if value := cfg.Getenv("WEATHER_ENSEMBLE_TIMEOUT"); value != "" {
+			timeout, err = time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("无效的WEATHER_ENSEMBLE_TIMEOUT: %s", value)
+			}
+		}
+
+		members := make([]Provider, 0, len(names))
+		for _, name := range names {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if name == ensembleProvider {
+				return nil, fmt.Errorf("WEATHER_ENSEMBLE_PROVIDERS不能包含%s", ensembleProvider)
+			}
+			member, err := NewProvider(name, cfg)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		}
+		if len(members) < 2 {
+			return nil, fmt.Errorf("WEATHER_ENSEMBLE_PROVIDERS至少需要两个数据源")
+		}
+
+		return NewEnsembleProvider(members, cfg.Getenv("WEATHER_ENSEMBLE_METHOD"), weights, timeout)
+	})
+}
+
+// ensembleService 同时查询多个数据源并合并为一致的预报
+type ensembleService struct {
+	members []Provider
+	method  string
+	weights map[string]float64
+	timeout time.Duration
+}
+
+// NewEnsembleProvider 创建集合预报数据源。method为median（默认）或weighted，
+// weighted按weights中数据源的权重取加权平均，未列出的数据源权重为1；
+// timeout为单个成员的查询超时，不大于0时使用默认值10秒
+func NewEnsembleProvider(members []Provider, method string, weights map[string]float64, timeout time.Duration) (Provider, error) {
+	switch method {
+	case "":
+		method = EnsembleMedian
+	case EnsembleMedian, EnsembleWeighted:
+	default:
+		return nil, fmt.Errorf("未知的集合预报合并方式: %s，可选: %s、%s", method, EnsembleMedian, EnsembleWeighted)
+	}
+	if timeout <= 0 {
+		timeout = defaultEnsembleTimeout
+	}
+	return &ensembleService{members: members, method: method, weights: weights, timeout: timeout}, nil
+}
+
+// Name 返回数据源名称
+func (s *ensembleService) Name() string {
+	return ensembleProvider
+}
+
+// Capabilities 返回任一成员具备的能力，覆盖范围取并集
+func (s *ensembleService) Capabilities() bean.ProviderCapabilities {
+	var caps bean.ProviderCapabilities
+	coverages := make([]string, len(s.members))
+	for i, m := range s.members {
+		c := m.Capabilities()
+		caps.HourlyData = caps.HourlyData || c.HourlyData
+		caps.DailyData = caps.DailyData || c.DailyData
+		caps.Adcodes = caps.Adcodes || c.Adcodes
+		coverages[i] = c.Coverage
+	}
+	caps.Coverage = coverageUnion(coverages...)
+	return caps
+}
+
+// ensembleResult 单个成员的查询结果
+type ensembleResult struct {
+	provider Provider
+	response *bean.WeatherResponse
+	err      error
+}
+
+// GetHourlyWeather 并发查询所有成员，合并成功返回的结果。没有真实逐小时数据的成员
+// 只参与当前天气的合并；单个成员超时不影响其他成员；地点有歧义时直接返回歧义错误
+func (s *ensembleService) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	results := make([]ensembleResult, len(s.members))
+	var wg sync.WaitGroup
+	for i, m := range s.members {
+		wg.Add(1)
+		go func(i int, m Provider) {
+			defer wg.Done()
+			memberCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			response, err := m.GetHourlyWeather(memberCtx, location)
+			if err != nil && ctx.Err() == nil && errors.Is(memberCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("查询超时（%s）: %w", s.timeout, err)
+			}
+			results[i] = ensembleResult{provider: m, response: response, err: err}
+		}(i, m)
+	}
+	wg.Wait()
+
+	succeeded := make([]ensembleResult, 0, len(results))
+	var errs []string
+	var notFound error
+	for _, r := range results {
+		if r.err == nil {
+			succeeded = append(succeeded, r)
+			continue
+		}
+
+		var ambiguous *bean.AmbiguousLocationError
+		var missing *bean.LocationNotFoundError
+		switch {
+		case errors.As(r.err, &ambiguous):
+			return nil, r.err
+		case errors.As(r.err, &missing):
+			notFound = r.err
+		default:
+			logging.Warningf(ctx, ensembleLogger, "数据源%s查询失败，不参与合并: %v", r.provider.Name(), r.err)
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", r.provider.Name(), r.err))
+	}
+
+	if len(succeeded) == 0 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if notFound != nil {
+			return nil, notFound
+		}
+		return nil, fmt.Errorf("%w: %s", bean.ErrProviderUnavailable, strings.Join(errs, "；"))
+	}
+	return s.merge(succeeded), nil
+}
+
+// merge 合并各成员的结果，地点、逐日预报等非数值信息取第一个成功的成员
+func (s *ensembleService) merge(results []ensembleResult) *bean.WeatherResponse {
+	first := results[0].response
+	merged := &bean.WeatherResponse{
+		Location:      first.Location,
+		LocationKey:   first.LocationKey,
+		Country:       first.Country,
+		ForecastTime:  first.ForecastTime,
+		Provider:      ensembleProvider,
+		Sources:       make([]string, len(results)),
+		DailyForecast: first.DailyForecast,
+	}
+
+	currents := make([]weightedConditions, len(results))
+	var hourly [][]bean.HourlyForecast
+	var hourlyWeights []float64
+	seenWarnings := make(map[string]bool)
+	for i, r := range results {
+		name := r.provider.Name()
+		merged.Sources[i] = name
+		currents[i] = weightedConditions{conditions: r.response.CurrentConditions, weight: s.weight(name)}
+		if r.provider.Capabilities().HourlyData {
+			hourly = append(hourly, r.response.HourlyForecast)
+			hourlyWeights = append(hourlyWeights, s.weight(name))
+		}
+		if merged.DailyForecast == nil {
+			merged.DailyForecast = r.response.DailyForecast
+		}
+		for _, w := range r.response.Warnings {
+			if !seenWarnings[w.ID] {
+				seenWarnings[w.ID] = true
+				merged.Warnings = append(merged.Warnings, w)
+			}
+		}
+	}
+
+	// 所有成员都没有真实逐小时数据时，退而合并它们推算的逐小时预报
+	if len(hourly) == 0 {
+		for _, r := range results {
+			hourly = append(hourly, r.response.HourlyForecast)
+			hourlyWeights = append(hourlyWeights, s.weight(r.provider.Name()))
+		}
+	}
+
+	merged.CurrentConditions = s.mergeCurrent(currents)
+	merged.HourlyForecast = s.mergeHourly(hourly, hourlyWeights)
+	return merged
+}
+
+// weightedConditions 带权重的当前天气
+type weightedConditions struct {
+	conditions bean.CurrentConditions
+	weight     float64
+}
+
+// mergeCurrent 合并当前天气。各数据源的天气描述语言和措辞不同，无法直接比较，
+// 按是否有降水取多数，天气描述取与之一致的第一个成员
+func (s *ensembleService) mergeCurrent(currents []weightedConditions) bean.CurrentConditions {
+	temps := make([]float64, len(currents))
+	humidities := make([]float64, len(currents))
+	precipitation := make([]bool, len(currents))
+	weights := make([]float64, len(currents))
+	for i, c := range currents {
+		temps[i] = celsius(c.conditions.Temperature)
+		humidities[i] = float64(c.conditions.RelativeHumidity)
+		precipitation[i] = c.conditions.Precipitation
+		weights[i] = c.weight
+	}
+
+	precipitating, agreement := modeValue(precipitation)
+	text := currents[0].conditions.WeatherText
+	for _, c := range currents {
+		if c.conditions.Precipitation == precipitating {
+			text = c.conditions.WeatherText
+			break
+		}
+	}
+
+	return bean.CurrentConditions{
+		Temperature:      bean.Temperature{Value: round1(s.combine(temps, weights)), Unit: "C"},
+		WeatherText:      text,
+		RelativeHumidity: int(s.combine(humidities, weights) + 0.5),
+		Precipitation:    precipitating,
+		ObservationTime:  currents[0].conditions.ObservationTime,
+		Ensemble: &bean.EnsembleSpread{
+			Members:                    len(currents),
+			TemperatureSpread:          round1(spread(temps)),
+			PrecipitationTypeAgreement: agreement,
+		},
+	}
+}
+
+// hourEntry 某个成员在某小时的预报
+type hourEntry struct {
+	member   int
+	forecast bean.HourlyForecast
+}
+
+// mergeHourly 按时段开始时间对齐合并逐小时预报，某小时只有部分成员有数据时只合并这些成员。
+// 降水类型按规范化后的值取多数，天气描述、降水强度取降水类型与之一致的第一个成员
+func (s *ensembleService) mergeHourly(hourly [][]bean.HourlyForecast, weights []float64) []bean.HourlyForecast {
+	slots := alignHours(hourly)
+	result := make([]bean.HourlyForecast, 0, len(slots))
+	for _, slot := range slots {
+		temps := make([]float64, len(slot))
+		probabilities := make([]float64, len(slot))
+		types := make([]string, len(slot))
+		hourWeights := make([]float64, len(slot))
+		for i, e := range slot {
+			temps[i] = celsius(e.forecast.Temperature)
+			probabilities[i] = float64(e.forecast.PrecipitationProbability)
+			types[i] = normalizePrecipitationType(e.forecast.PrecipitationType)
+			hourWeights[i] = weights[e.member]
+		}
+
+		precipType, agreement := modeValue(types)
+		representative := slot[0].forecast
+		for i, e := range slot {
+			if types[i] == precipType {
+				representative = e.forecast
+				break
+			}
+		}
+
+		n := len(result) + 1
+		relativeTime := fmt.Sprintf("+%d hour", n)
+		if n > 1 {
+			relativeTime += "s"
+		}
+
+		result = append(result, bean.HourlyForecast{
+			RelativeTime:             relativeTime,
+			DateTime:                 representative.DateTime,
+			Temperature:              bean.Temperature{Value: round1(s.combine(temps, hourWeights)), Unit: "C"},
+			WeatherText:              representative.WeatherText,
+			PrecipitationProbability: int(s.combine(probabilities, hourWeights) + 0.5),
+			PrecipitationType:        representative.PrecipitationType,
+			PrecipitationIntensity:   representative.PrecipitationIntensity,
+			Ensemble: &bean.EnsembleSpread{
+				Members:                        len(slot),
+				TemperatureSpread:              round1(spread(temps)),
+				PrecipitationProbabilitySpread: int(spread(probabilities)),
+				PrecipitationTypeAgreement:     agreement,
+			},
+		})
+	}
+	return result
+}
+
+// alignHours 将各成员的逐小时预报按时段开始时间分组，按时间先后排列。
+// 有成员的预报缺少时间时无法对齐，退而按下标对齐
+func alignHours(hourly [][]bean.HourlyForecast) [][]hourEntry {
+	slots := make(map[int64][]hourEntry)
+	for member, forecast := range hourly {
+		for _, f := range forecast {
+			t, err := time.Parse(time.RFC3339, f.DateTime)
+			if err != nil {
+				return alignHoursByIndex(hourly)
+			}
+			key := t.Truncate(time.Hour).Unix()
+			slots[key] = append(slots[key], hourEntry{member: member, forecast: f})
+		}
+	}
+
+	keys := make([]int64, 0, len(slots))
+	for key := range slots {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	result := make([][]hourEntry, len(keys))
+	for i, key := range keys {
+		result[i] = slots[key]
+	}
+	return result
+}
+
+// alignHoursByIndex 按下标对齐各成员的逐小时预报
+func alignHoursByIndex(hourly [][]bean.HourlyForecast) [][]hourEntry {
+	var result [][]hourEntry
+	for member, forecast := range hourly {
+		for i, f := range forecast {
+			if i >= len(result) {
+				result = append(result, nil)
+			}
+			result[i] = append(result[i], hourEntry{member: member, forecast: f})
+		}
+	}
+	return result
+}
+
+// normalizePrecipitationType 规范化降水类型以便比较，未填写视为None
+func normalizePrecipitationType(precipType string) string {
+	precipType = strings.ToLower(strings.TrimSpace(precipType))
+	if precipType == "" {
+		return "none"
+	}
+	return precipType
+}
+
+// combine 按合并方式计算中位数或加权平均
+func (s *ensembleService) combine(values, weights []float64) float64 {
+	if s.method == EnsembleWeighted {
+		var sum, total float64
+		for i, v := range values {
+			sum += v * weights[i]
+			total += weights[i]
+		}
+		if total > 0 {
+			return sum / total
+		}
+	}
+	return median(values)
+}
+
+// weight 返回数据源的权重
+func (s *ensembleService) weight(name string) float64 {
+	if w, found := s.weights[name]; found {
+		return w
+	}
+	return 1
+}
+
+// parseEnsembleWeights 解析"数据源=权重"以逗号分隔的权重配置
+func parseEnsembleWeights(value string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, weightStr, found := strings.Cut(item, "=")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+		if !found || err != nil || weight < 0 {
+			return nil, fmt.Errorf("无效的WEATHER_ENSEMBLE_WEIGHTS: %s", item)
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+	return weights, nil
+}
+
+// celsius 将温度统一为摄氏度
+func celsius(t bean.Temperature) float64 {
+	if strings.EqualFold(t.Unit, "F") {
+		return (t.Value - 32) * 5 / 9
+	}
+	return t.Value
+}
+
+// median 计算中位数
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// spread 计算最大值与最小值之差
+func spread(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	return hi - lo
+}
+
+// modeValue 返回出现次数最多的值（并列时取先出现的）及其占比
+func modeValue[T comparable](values []T) (T, float64) {
+	var best T
+	if len(values) == 0 {
+		return best, 0
+	}
+	counts := make(map[T]int)
+	best = values[0]
+	for _, v := range values {
+		counts[v]++
+		if counts[v] > counts[best] {
+			best = v
+		}
+	}
+	return best, round2(float64(counts[best]) / float64(len(values)))
+}
+
+// round1 保留一位小数
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
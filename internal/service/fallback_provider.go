@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// fallbackLogger 数据源降级的日志名称
+const fallbackLogger = "fallback"
+
+// FallbackOptions 降级链的熔断参数，零值使用默认值
+type FallbackOptions struct {
+	FailureThreshold int           // 连续失败多少次后熔断，默认5
+	Cooldown         time.Duration // 熔断后多久进入半开状态，默认30秒
+}
+
+// fallbackMember 降级链中的数据源及其熔断器
+type fallbackMember struct {
+	provider Provider
+	breaker  *circuitBreaker
+}
+
+// fallbackProvider 按顺序尝试多个数据源，前一个失败或已熔断时使用下一个
+type fallbackProvider struct {
+	members []fallbackMember
+}
+
+// NewFallbackProvider 创建按顺序降级的数据源，每个数据源有独立的熔断器
+func NewFallbackProvider(providers []Provider, opts FallbackOptions) Provider {
+	members := make([]fallbackMember, len(providers))
+	for i, p := range providers {
+		members[i] = fallbackMember{
+			provider: p,
+			breaker:  newCircuitBreaker(opts.FailureThreshold, opts.Cooldown),
+		}
+	}
+	return &fallbackProvider{members: members}
+}
+
+// Name 返回按降级顺序排列的数据源名称
+func (p *fallbackProvider) Name() string {
+	names := make([]string, len(p.members))
+	for i, m := range p.members {
+		names[i] = m.provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Capabilities 返回任一数据源具备的能力，覆盖范围取并集。
+// 降级链中总有一个数据源应答，应答的数据源具备哪些能力取决于查询的地点和当时的故障情况
+func (p *fallbackProvider) Capabilities() bean.ProviderCapabilities {
+	var caps bean.ProviderCapabilities
+	coverages := make([]string, len(p.members))
+	for i, m := range p.members {
+		c := m.provider.Capabilities()
+		caps.HourlyData = caps.HourlyData || c.HourlyData
+		caps.DailyData = caps.DailyData || c.DailyData
+		caps.Adcodes = caps.Adcodes || c.Adcodes
+		coverages[i] = c.Coverage
+	}
+	caps.Coverage = coverageUnion(coverages...)
+	return caps
+}
+
+// GetHourlyWeather 依次向未熔断的数据源查询天气。地点有歧义时直接返回，
+// 地点找不到时换下一个数据源但不计入熔断
+func (p *fallbackProvider) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	var errs []string
+	var notFound error
+	for _, m := range p.members {
+		name := m.provider.Name()
+		if !m.breaker.allow() {
+			logging.Debugf(ctx, fallbackLogger, "数据源%s已熔断，跳过", name)
+			errs = append(errs, name+": 已熔断")
+			continue
+		}
+
+		response, err := m.provider.GetHourlyWeather(ctx, location)
+		if err == nil {
+			if m.breaker.success() {
+				logging.Infof(ctx, fallbackLogger, "数据源%s已恢复", name)
+			}
+			return response, nil
+		}
+
+		var ambiguous *bean.AmbiguousLocationError
+		var missing *bean.LocationNotFoundError
+		switch {
+		case ctx.Err() != nil:
+			m.breaker.release()
+			return nil, err
+		case errors.As(err, &ambiguous):
+			m.breaker.release()
+			return nil, err
+		case errors.As(err, &missing):
+			// 地点不在该数据源的覆盖范围内，数据源本身是正常的
+			m.breaker.success()
+			notFound = err
+			continue
+		}
+
+		if m.breaker.failure() {
+			logging.Warningf(ctx, fallbackLogger, "数据源%s连续失败，已熔断: %v", name, err)
+		} else {
+			logging.Warningf(ctx, fallbackLogger, "数据源%s查询失败: %v", name, err)
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+	}
+
+	if notFound != nil {
+		return nil, notFound
+	}
+	return nil, fmt.Errorf("%w: %s", bean.ErrProviderUnavailable, strings.Join(errs, "；"))
+}
+
+// NewProviderChain 按名称依次创建数据源，只有一个时直接返回该数据源，多个时组成降级链
+func NewProviderChain(names []string, cfg ProviderConfig, opts FallbackOptions) (Provider, error) {
+	providers := make([]Provider, 0, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		provider, err := NewProvider(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("未配置天气数据源")
+	case 1:
+		return providers[0], nil
+	}
+	return NewFallbackProvider(providers, opts), nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tung/mcp/internal/logging"
+)
+
+// fixtureLogger 录制与回放的日志名称
+const fixtureLogger = "fixture"
+
+// 上游请求模式
+const (
+	UpstreamLive   = "live"
+	UpstreamRecord = "record"
+	UpstreamReplay = "replay"
+)
+
+// fixtureNamePattern 生成文件名时需要替换的字符
+var fixtureNamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fixture 录制在磁盘上的上游响应
+type fixture struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"` // 已隐藏密钥的请求地址
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+	RecordedAt  string `json:"recorded_at,omitempty"`
+}
+
+// fixturePath 根据请求生成录制文件的路径。文件名由主机、路径和请求地址的摘要组成，
+// 三者都取自隐藏密钥并排序查询参数后的地址，因此回放时不需要真实密钥，
+// 放在路径中的密钥（例如彩云天气的令牌）也不会出现在文件名中
+func fixturePath(dir string, req *http.Request) (string, string) {
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+	redacted := redactAPIKey(u.String())
+	key := req.Method + " " + redacted
+	if parsed, err := url.Parse(redacted); err == nil {
+		u = *parsed
+	}
+
+	sum := sha1.Sum([]byte(key))
+	name := strings.Trim(fixtureNamePattern.ReplaceAllString(u.Path, "_"), "_")
+	if name == "" {
+		name = "root"
+	}
+	host := fixtureNamePattern.ReplaceAllString(u.Host, "_")
+	return filepath.Join(dir, host, name+"-"+hex.EncodeToString(sum[:6])+".json"), key
+}
+
+// recordingTransport 将上游响应保存为录制文件的传输层
+type recordingTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// NewRecordingTransport 创建录制传输层，请求照常发往上游，成功收到的响应写入dir
+func NewRecordingTransport(dir string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordingTransport{dir: dir, next: next}
+}
+
+// RoundTrip 转发请求并录制响应，录制失败不影响请求本身
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	path, key := fixturePath(t.dir, req)
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(fixture{
+		Method:      req.Method,
+		URL:         strings.TrimPrefix(key, req.Method+" "),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+		RecordedAt:  time.Now().Format(time.RFC3339),
+	})
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, data.Bytes(), 0644)
+		}
+	}
+	if err != nil {
+		logging.Warningf(req.Context(), fixtureLogger, "保存录制文件失败 %s: %v", path, err)
+	} else {
+		logging.Debugf(req.Context(), fixtureLogger, "已录制 %s -> %s", key, path)
+	}
+	return resp, nil
+}
+
+// ReplayOptions 回放选项
+type ReplayOptions struct {
+	Latency   time.Duration // 每个请求模拟的延迟
+	FailHosts []string      // 模拟故障的上游主机，请求这些主机总是失败
+}
+
+// replayTransport 从录制文件返回响应的传输层，不访问网络
+type replayTransport struct {
+	dir  string
+	opts ReplayOptions
+}
+
+// NewReplayTransport 创建回放传输层，没有对应录制文件的请求返回错误
+func NewReplayTransport(dir string, opts ReplayOptions) http.RoundTripper {
+	return &replayTransport{dir: dir, opts: opts}
+}
+
+// RoundTrip 按请求查找录制文件并返回其中的响应
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.opts.Latency > 0 {
+		if err := sleepContext(req.Context(), t.opts.Latency); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, host := range t.opts.FailHosts {
+		if strings.EqualFold(host, req.URL.Hostname()) {
+			return nil, fmt.Errorf("模拟的上游故障: %s", host)
+		}
+	}
+
+	path, key := fixturePath(t.dir, req)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("没有录制的响应: %s（%s）", key, path)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("解析录制文件失败 %s: %w", path, err)
+	}
+
+	header := make(http.Header)
+	if f.ContentType != "" {
+		header.Set("Content-Type", f.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+// sleepContext 等待一段时间，上下文取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFixtureRequest 创建发往url的GET请求
+func newFixtureRequest(t *testing.T, rawURL string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest(%q) error = %v", rawURL, err)
+	}
+	return req
+}
+
+// roundTrip 通过传输层发送GET请求，返回状态码和响应体
+func roundTrip(t *testing.T, transport http.RoundTripper, rawURL string) (int, string, error) {
+	t.Helper()
+	resp, err := transport.RoundTrip(newFixtureRequest(t, rawURL))
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+func TestFixturePathStable(t *testing.T) {
+	dir := t.TempDir()
+	path, key := fixturePath(dir, newFixtureRequest(t, "https://restapi.amap.com/v3/weather/weatherInfo?key=secret1&city=110000&extensions=base"))
+
+	if want := filepath.Join(dir, "restapi.amap.com", "v3_weather_weatherInfo-"); !strings.HasPrefix(path, want) || !strings.HasSuffix(path, ".json") {
+		t.Errorf("fixturePath() = %q, want prefix %q", path, want)
+	}
+	if strings.Contains(key, "secret1") || !strings.Contains(key, "key=***") {
+		t.Errorf("fixturePath() key = %q, want redacted key", key)
+	}
+
+	// 密钥不同、参数顺序不同的同一请求对应同一个录制文件
+	for _, rawURL := range []string{
+		"https://restapi.amap.com/v3/weather/weatherInfo?key=secret2&city=110000&extensions=base",
+		"https://restapi.amap.com/v3/weather/weatherInfo?extensions=base&city=110000&key=secret1",
+	} {
+		if got, _ := fixturePath(dir, newFixtureRequest(t, rawURL)); got != path {
+			t.Errorf("fixturePath(%q) = %q, want %q", rawURL, got, path)
+		}
+	}
+
+	// 参数值不同的请求对应不同的录制文件
+	other, _ := fixturePath(dir, newFixtureRequest(t, "https://restapi.amap.com/v3/weather/weatherInfo?key=secret1&city=310000&extensions=base"))
+	if other == path {
+		t.Errorf("fixturePath() for a different city = %q, want a different file", other)
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	transport := NewReplayTransport(t.TempDir(), ReplayOptions{})
+	_, _, err := roundTrip(t, transport, "https://restapi.amap.com/v3/weather/weatherInfo?key=secret&city=110000")
+	if err == nil || !strings.Contains(err.Error(), "没有录制的响应") {
+		t.Fatalf("RoundTrip() error = %v, want missing fixture error", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("RoundTrip() error = %v, leaks the key", err)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"city":"` + r.URL.Query().Get("city") + `"}`))
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	status, body, err := roundTrip(t, NewRecordingTransport(dir, nil), upstream.URL+"/v3/weather?key=real-key&city=110000")
+	if err != nil || status != http.StatusAccepted || body != `{"city":"110000"}` {
+		t.Fatalf("recording RoundTrip() = %d %q %v", status, body, err)
+	}
+
+	// 录制文件中不含真实密钥
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded files = %v, want exactly one", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "real-key") || strings.Contains(files[0], "real-key") {
+		t.Errorf("recorded fixture %s leaks the key:\n%s", files[0], data)
+	}
+
+	// 上游关闭后用另一个密钥回放
+	upstream.Close()
+	status, body, err = roundTrip(t, NewReplayTransport(dir, ReplayOptions{}), upstream.URL+"/v3/weather?city=110000&key=other-key")
+	if err != nil || status != http.StatusAccepted || body != `{"city":"110000"}` {
+		t.Errorf("replaying RoundTrip() = %d %q %v", status, body, err)
+	}
+}
+
+func TestRecordThenReplayPathSecret(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer upstream.Close()
+
+	// 彩云天气的令牌放在路径中，录制和回放使用不同的令牌
+	registerPathSecret("record-path-token")
+	registerPathSecret("replay-path-token")
+	dir := t.TempDir()
+	if _, _, err := roundTrip(t, NewRecordingTransport(dir, nil), upstream.URL+"/v2.6/record-path-token/116.4000,39.9000/minutely?unit=metric:v2"); err != nil {
+		t.Fatalf("recording RoundTrip() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded files = %v, want exactly one", files)
+	}
+	if strings.Contains(files[0], "record-path-token") {
+		t.Errorf("recorded file name %s leaks the path secret", files[0])
+	}
+
+	upstream.Close()
+	_, body, err := roundTrip(t, NewReplayTransport(dir, ReplayOptions{}), upstream.URL+"/v2.6/replay-path-token/116.4000,39.9000/minutely?unit=metric:v2")
+	if err != nil || body != `{"status":"ok"}` {
+		t.Errorf("replaying RoundTrip() = %q %v", body, err)
+	}
+}
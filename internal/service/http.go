@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// apiKeyPattern 请求地址中的密钥参数
+var apiKeyPattern = regexp.MustCompile(`(?i)\b(apikey|key|token)=[^&]*`)
+
+// pathSecrets 出现在请求路径中的密钥，记录日志和错误信息时同样需要隐藏
+var pathSecrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// registerPathSecret 登记放在请求路径而不是查询参数中的密钥
+func registerPathSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	pathSecrets.Lock()
+	defer pathSecrets.Unlock()
+	pathSecrets.values = append(pathSecrets.values, secret)
+}
+
+// upstreamClient 请求上游天气接口使用的客户端，录制和回放时替换其传输层
+var upstreamClient = http.DefaultClient
+
+// SetUpstreamTransport 替换请求上游天气接口的传输层，需在创建数据源之前调用
+func SetUpstreamTransport(transport http.RoundTripper) {
+	upstreamClient = &http.Client{Transport: transport}
+}
+
+// httpGet 发起可随上下文取消的GET请求，返回的错误中不包含密钥
+func httpGet(ctx context.Context, rawURL string) (*http.Response, error) {
+	return httpGetWithHeader(ctx, rawURL, nil)
+}
+
+// httpGetWithHeader 发起带额外请求头的GET请求
+func httpGetWithHeader(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactAPIKey(urlErr.URL)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// redactAPIKey 隐藏请求地址中的密钥，用于日志和错误信息
+func redactAPIKey(rawURL string) string {
+	rawURL = apiKeyPattern.ReplaceAllString(rawURL, "$1=***")
+	pathSecrets.RLock()
+	defer pathSecrets.RUnlock()
+	for _, secret := range pathSecrets.values {
+		rawURL = strings.ReplaceAll(rawURL, secret, "***")
+	}
+	return rawURL
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// caiyunLogger 彩云天气服务的日志名称
+const caiyunLogger = "caiyun"
+
+// caiyunProvider 彩云天气短时降水数据源名称
+const caiyunProvider = "caiyun"
+
+// defaultCaiyunBaseURL 彩云天气默认接口地址
+const defaultCaiyunBaseURL = "https://api.caiyunapp.com/v2.6"
+
+// nowcastRainThreshold 视为有降水的最小强度，单位：毫米/小时
+const nowcastRainThreshold = 0.08
+
+// NowcastProvider 分钟级短时降水数据源接口
+type NowcastProvider interface {
+	Name() string
+	GetNowcast(ctx context.Context, lat, lon float64) (*bean.NowcastResponse, error)
+}
+
+// caiyunNowcastService 彩云天气分钟级降水服务实现
+type caiyunNowcastService struct {
+	apiKey  string
+	baseURL string
+}
+
+// NewCaiyunNowcastService 创建新的彩云天气分钟级降水服务，baseURL为空时使用官方接口
+func NewCaiyunNowcastService(apiKey, baseURL string) NowcastProvider {
+	if baseURL == "" {
+		baseURL = defaultCaiyunBaseURL
+	}
+	// 彩云天气的令牌放在请求路径中
+	registerPathSecret(apiKey)
+	return &caiyunNowcastService{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Name 返回数据源名称
+func (s *caiyunNowcastService) Name() string {
+	return caiyunProvider
+}
+
+// GetNowcast 获取未来2小时逐分钟降水预报
+func (s *caiyunNowcastService) GetNowcast(ctx context.Context, lat, lon float64) (*bean.NowcastResponse, error) {
+	rawURL := fmt.Sprintf("%s/%s/%s,%s/minutely?unit=metric:v2", s.baseURL, s.apiKey,
+		strconv.FormatFloat(lon, 'f', 4, 64), strconv.FormatFloat(lat, 'f', 4, 64))
+
+	logging.Infof(ctx, caiyunLogger, "请求彩云天气接口: %s", redactAPIKey(rawURL))
+	resp, err := httpGet(ctx, rawURL)
+	if err != nil {
+		logging.Errorf(ctx, caiyunLogger, "获取短时降水失败 %.4f,%.4f: %v", lat, lon, err)
+		return nil, fmt.Errorf("%w: %w", bean.ErrNowcastFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", bean.ErrNowcastFailed, err)
+	}
+
+	var minutely bean.CaiyunMinutelyResponse
+	if err := json.Unmarshal(body, &minutely); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%w: API请求失败，状态码: %d", bean.ErrNowcastFailed, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%w: %w", bean.ErrNowcastFailed, err)
+	}
+	if minutely.Status != "ok" {
+		logging.Errorf(ctx, caiyunLogger, "获取短时降水失败 %.4f,%.4f: %s", lat, lon, minutely.Error)
+		return nil, fmt.Errorf("%w: API返回错误: %s", bean.ErrNowcastFailed, minutely.Error)
+	}
+
+	return s.buildNowcastResponse(lat, lon, &minutely), nil
+}
+
+// buildNowcastResponse 构建短时降水响应
+func (s *caiyunNowcastService) buildNowcastResponse(lat, lon float64, minutely *bean.CaiyunMinutelyResponse) *bean.NowcastResponse {
+	data := minutely.Result.Minutely
+	response := &bean.NowcastResponse{
+		Latitude:    lat,
+		Longitude:   lon,
+		Description: data.Description,
+		Probability: make([]int, len(data.Probability)),
+		Minutely:    make([]bean.NowcastMinute, len(data.Precipitation2h)),
+		Provider:    caiyunProvider,
+	}
+	if minutely.ServerTime > 0 {
+		response.ForecastTime = time.Unix(minutely.ServerTime, 0).Format(time.RFC3339)
+	}
+
+	for i, p := range data.Probability {
+		response.Probability[i] = int(math.Round(p * 100))
+	}
+
+	for i, intensity := range data.Precipitation2h {
+		response.Minutely[i] = bean.NowcastMinute{
+			Minute:    i,
+			Intensity: intensity,
+			Level:     nowcastLevel(intensity),
+		}
+		if intensity > response.MaxIntensity {
+			response.MaxIntensity = intensity
+		}
+		if intensity >= nowcastRainThreshold && response.RainStartsIn == nil {
+			minute := i
+			response.RainStartsIn = &minute
+		}
+	}
+	response.RainInNextHour = response.RainStartsIn != nil && *response.RainStartsIn < 60
+
+	return response
+}
+
+// nowcastLevel 根据降水强度（毫米/小时）判断强度等级
+func nowcastLevel(intensity float64) string {
+	switch {
+	case intensity < nowcastRainThreshold:
+		return bean.PrecipitationNone
+	case intensity < 3.44:
+		return bean.PrecipitationLight
+	case intensity < 11.33:
+		return bean.PrecipitationModerate
+	case intensity < 51.3:
+		return bean.PrecipitationHeavy
+	}
+	return bean.PrecipitationStorm
+}
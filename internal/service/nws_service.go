@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// nwsLogger 美国国家气象局服务的日志名称
+const nwsLogger = "nws"
+
+// nwsProvider 美国国家气象局数据源名称
+const nwsProvider = "nws"
+
+// defaultNWSBaseURL 美国国家气象局默认接口地址
+const defaultNWSBaseURL = "https://api.weather.gov"
+
+// nwsHourlyForecasts 返回的逐小时预报数量
+const nwsHourlyForecasts = 12
+
+// nwsContactPattern User-Agent中的联系方式：邮箱、网址或域名，例如 ops@example.com、weather.example.com
+var nwsContactPattern = regexp.MustCompile(`(?i)[^\s@(),;]+@[a-z0-9-]+(\.[a-z0-9-]+)+|\b(https?://)?[a-z0-9-]+(\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+
+// nwsIcePattern 冻雨、冻毛毛雨、雨夹雪和冰粒按整词匹配，"Freezing Fog"等不含降水的描述不算作冰
+var nwsIcePattern = regexp.MustCompile(`\b(freezing rain|freezing drizzle|sleet|ice pellets)\b`)
+
+func init() {
+	RegisterProvider(nwsProvider, func(cfg ProviderConfig) (Provider, error) {
+		// 美国国家气象局接口不需要API密钥，但要求User-Agent中带有联系方式
+		return NewNWSService(cfg.Getenv("NWS_BASE_URL"), cfg.Getenv("NWS_USER_AGENT"))
+	})
+}
+
+// nwsService 美国国家气象局天气服务实现
+type nwsService struct {
+	baseURL       string
+	userAgent     string
+	locationCache *cache.Cache
+	cacheDir      string
+	cacheFile     string
+}
+
+// NewNWSService 创建新的美国国家气象局天气服务，baseURL为空时使用默认地址。
+// 接口要求User-Agent标明应用并带有联系方式（邮箱或网址），例如 (weather.example.com, ops@example.com)
+func NewNWSService(baseURL, userAgent string) (Provider, error) {
+	if baseURL == "" {
+		baseURL = defaultNWSBaseURL
+	}
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return nil, fmt.Errorf("使用nws数据源时必须设置NWS_USER_AGENT，并带有联系方式，例如 (weather.example.com, ops@example.com)")
+	}
+	if !nwsContactPattern.MatchString(userAgent) {
+		return nil, fmt.Errorf("NWS_USER_AGENT需要带有联系方式（邮箱或网址）: %s", userAgent)
+	}
+
+	// 创建缓存目录
+	homeDir, _ := os.UserHomeDir()
+	cacheDir := filepath.Join(homeDir, ".cache", "nws")
+	cacheFile := filepath.Join(cacheDir, "gridpoint_cache.json")
+
+	// 确保缓存目录存在
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		os.MkdirAll(cacheDir, 0755)
+	}
+
+	// 初始化缓存
+	c := cache.New(24*time.Hour, 1*time.Hour)
+
+	// 尝试从文件加载缓存
+	if _, err := os.Stat(cacheFile); err == nil {
+		data, err := os.ReadFile(cacheFile)
+		if err == nil {
+			var cacheData map[string]bean.NWSGridpoint
+			if err := json.Unmarshal(data, &cacheData); err == nil {
+				for k, v := range cacheData {
+					c.Set(k, v, cache.NoExpiration)
+				}
+			}
+		}
+	}
+
+	return &nwsService{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		userAgent:     userAgent,
+		locationCache: c,
+		cacheDir:      cacheDir,
+		cacheFile:     cacheFile,
+	}, nil
+}
+
+// Name 返回数据源名称
+func (s *nwsService) Name() string {
+	return nwsProvider
+}
+
+// Capabilities 返回数据源能力，美国国家气象局提供美国范围的真实逐小时和逐12小时预报
+func (s *nwsService) Capabilities() bean.ProviderCapabilities {
+	return bean.ProviderCapabilities{
+		HourlyData: true,
+		DailyData:  true,
+		Coverage:   bean.ProviderCoverageUS,
+		Adcodes:    false,
+	}
+}
+
+// GetHourlyWeather 获取每小时天气预报，location为"纬度,经度"。依次查询坐标点、
+// 网格逐12小时预报和网格逐小时预报，逐小时预报的第一个时段作为当前天气
+func (s *nwsService) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	lat, lon, ok := parseCoordinates(location)
+	if !ok {
+		return nil, &bean.LocationNotFoundError{Location: location}
+	}
+	// 接口只接受最多4位小数的坐标
+	pointKey := strconv.FormatFloat(lat, 'f', 4, 64) + "," + strconv.FormatFloat(lon, 'f', 4, 64)
+
+	// 尝试从缓存获取预报网格
+	gridpoint, found := s.getCachedGridpoint(pointKey)
+	if found {
+		logging.Debugf(ctx, nwsLogger, "命中预报网格缓存: %s -> %s/%d,%d", pointKey, gridpoint.GridID, gridpoint.GridX, gridpoint.GridY)
+	} else {
+		var err error
+		gridpoint, err = s.getGridpoint(ctx, pointKey)
+		var notFound *bean.LocationNotFoundError
+		if errors.As(err, &notFound) {
+			// 坐标不在美国范围内
+			return nil, &bean.LocationNotFoundError{Location: location}
+		}
+		if err != nil {
+			logging.Errorf(ctx, nwsLogger, "获取预报网格失败 %s: %v", pointKey, err)
+			return nil, fmt.Errorf("获取预报网格失败: %w", err)
+		}
+		// 缓存预报网格
+		s.cacheGridpoint(pointKey, gridpoint)
+	}
+
+	// 逐12小时预报是补充信息，失败时只记录日志
+	var forecast bean.NWSForecastResponse
+	if err := s.getJSON(ctx, gridpoint.Forecast, &forecast); err != nil {
+		logging.Warningf(ctx, nwsLogger, "获取网格预报失败 %s: %v", pointKey, err)
+	}
+
+	var hourly bean.NWSForecastResponse
+	if err := s.getJSON(ctx, gridpoint.ForecastHourly, &hourly); err != nil {
+		logging.Errorf(ctx, nwsLogger, "获取逐小时预报失败 %s: %v", pointKey, err)
+		return nil, fmt.Errorf("获取逐小时预报失败: %w", err)
+	}
+	periods := hourly.Properties.Periods
+	if len(periods) == 0 {
+		return nil, fmt.Errorf("获取逐小时预报失败: 没有预报数据")
+	}
+
+	name := gridpoint.City
+	if gridpoint.State != "" {
+		name += ", " + gridpoint.State
+	}
+	current := periods[0]
+	precipType, _ := nwsPrecipitation(current.ShortForecast)
+	return &bean.WeatherResponse{
+		Location:    name,
+		LocationKey: fmt.Sprintf("%s/%d,%d", gridpoint.GridID, gridpoint.GridX, gridpoint.GridY),
+		Country:     "United States",
+		CurrentConditions: bean.CurrentConditions{
+			Temperature:      nwsTemperature(current),
+			WeatherText:      current.ShortForecast,
+			RelativeHumidity: nwsQuantity(current.RelativeHumidity),
+			Precipitation:    precipType != "None",
+			ObservationTime:  current.StartTime,
+		},
+		HourlyForecast: s.formatHourlyForecast(periods[1:]),
+		ForecastTime:   hourly.Properties.UpdateTime,
+		DailyForecast:  s.formatDailyForecast(forecast.Properties.Periods),
+		Provider:       nwsProvider,
+	}, nil
+}
+
+// getGridpoint 查询坐标点对应的预报网格，坐标不在美国范围内时返回找不到地点
+func (s *nwsService) getGridpoint(ctx context.Context, pointKey string) (bean.NWSGridpoint, error) {
+	var points bean.NWSPointsResponse
+	if err := s.getJSON(ctx, s.baseURL+"/points/"+pointKey, &points); err != nil {
+		return bean.NWSGridpoint{}, err
+	}
+
+	p := points.Properties
+	if p.ForecastHourly == "" {
+		return bean.NWSGridpoint{}, &bean.LocationNotFoundError{Location: pointKey}
+	}
+
+	return bean.NWSGridpoint{
+		GridID:         p.GridID,
+		GridX:          p.GridX,
+		GridY:          p.GridY,
+		Forecast:       p.Forecast,
+		ForecastHourly: p.ForecastHourly,
+		City:           p.RelativeLocation.Properties.City,
+		State:          p.RelativeLocation.Properties.State,
+	}, nil
+}
+
+// getJSON 请求美国国家气象局接口并解析JSON响应
+func (s *nwsService) getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	header := http.Header{}
+	header.Set("User-Agent", s.userAgent)
+	header.Set("Accept", "application/geo+json")
+
+	logging.Infof(ctx, nwsLogger, "请求美国国家气象局接口: %s", rawURL)
+	resp, err := httpGetWithHeader(ctx, rawURL, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp bean.NWSErrorResponse
+		json.Unmarshal(body, &errResp)
+		if resp.StatusCode == http.StatusNotFound {
+			return &bean.LocationNotFoundError{Location: rawURL}
+		}
+		if errResp.Detail != "" {
+			return fmt.Errorf("API返回错误: %s", errResp.Detail)
+		}
+		return fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// formatHourlyForecast 格式化逐小时预报
+func (s *nwsService) formatHourlyForecast(periods []bean.NWSForecastPeriod) []bean.HourlyForecast {
+	result := make([]bean.HourlyForecast, 0, nwsHourlyForecasts)
+	for i, period := range periods {
+		if i >= nwsHourlyForecasts {
+			break
+		}
+
+		relativeTime := fmt.Sprintf("+%d hour", i+1)
+		if i > 0 {
+			relativeTime += "s"
+		}
+
+		precipType, precipIntensity := nwsPrecipitation(period.ShortForecast)
+		result = append(result, bean.HourlyForecast{
+			RelativeTime:             relativeTime,
+			DateTime:                 forecastDateTime(period.StartTime),
+			Temperature:              nwsTemperature(period),
+			WeatherText:              period.ShortForecast,
+			PrecipitationProbability: nwsQuantity(period.ProbabilityOfPrecipitation),
+			PrecipitationType:        precipType,
+			PrecipitationIntensity:   precipIntensity,
+		})
+	}
+	return result
+}
+
+// formatDailyForecast 将白天和夜间的逐12小时预报合并为逐日预报
+func (s *nwsService) formatDailyForecast(periods []bean.NWSForecastPeriod) []bean.DailyForecast {
+	result := make([]bean.DailyForecast, 0, len(periods)/2+1)
+	for i := 0; i < len(periods); i++ {
+		period := periods[i]
+		temp := nwsTemperature(period)
+		day := bean.DailyForecast{
+			Date:             strings.SplitN(period.StartTime, "T", 2)[0],
+			TempMax:          temp,
+			TempMin:          temp,
+			RelativeHumidity: nwsQuantity(period.RelativeHumidity),
+		}
+
+		if !period.IsDaytime {
+			// 第一个时段可能是当天夜间，只有夜间数据
+			day.WeatherTextNight = period.ShortForecast
+			result = append(result, day)
+			continue
+		}
+
+		day.WeatherTextDay = period.ShortForecast
+		if i+1 < len(periods) && !periods[i+1].IsDaytime {
+			night := periods[i+1]
+			day.TempMin = nwsTemperature(night)
+			day.WeatherTextNight = night.ShortForecast
+			i++
+		}
+		result = append(result, day)
+	}
+	return result
+}
+
+// getCachedGridpoint 从缓存获取预报网格
+func (s *nwsService) getCachedGridpoint(pointKey string) (bean.NWSGridpoint, bool) {
+	if value, found := s.locationCache.Get(pointKey); found {
+		return value.(bean.NWSGridpoint), true
+	}
+	return bean.NWSGridpoint{}, false
+}
+
+// cacheGridpoint 缓存预报网格
+func (s *nwsService) cacheGridpoint(pointKey string, gridpoint bean.NWSGridpoint) {
+	// 添加到内存缓存
+	s.locationCache.Set(pointKey, gridpoint, cache.NoExpiration)
+
+	// 保存到文件，读取、更新和写回期间持有文件锁
+	unlock := lockCacheFile(s.cacheFile)
+	defer unlock()
+	cacheData := make(map[string]bean.NWSGridpoint)
+
+	// 尝试从文件加载现有缓存
+	if _, err := os.Stat(s.cacheFile); err == nil {
+		data, err := os.ReadFile(s.cacheFile)
+		if err == nil {
+			json.Unmarshal(data, &cacheData)
+		}
+	}
+
+	// 更新缓存
+	cacheData[pointKey] = gridpoint
+
+	// 保存回文件
+	data, err := json.Marshal(cacheData)
+	if err == nil {
+		writeCacheFile(s.cacheFile, data)
+	}
+}
+
+// nwsTemperature 将时段温度统一为摄氏度
+func nwsTemperature(period bean.NWSForecastPeriod) bean.Temperature {
+	value := celsius(bean.Temperature{Value: period.Temperature, Unit: period.TemperatureUnit})
+	return bean.Temperature{Value: round1(value), Unit: "C"}
+}
+
+// nwsQuantity 返回百分比数值，没有数据时为0
+func nwsQuantity(q bean.NWSQuantity) int {
+	if q.Value == nil {
+		return 0
+	}
+	return int(math.Round(*q.Value))
+}
+
+// nwsPrecipitation 根据简短描述判断降水类型和强度，同时有雨和雪时为Mixed，
+// 例如 "Rain And Snow Showers"、"Wintry Mix"
+func nwsPrecipitation(shortForecast string) (precipType, intensity string) {
+	text := strings.ToLower(shortForecast)
+	snow := strings.Contains(text, "snow") || strings.Contains(text, "flurries")
+	// "Snow Showers"中的showers是阵雪，不算作雨
+	showers := strings.Count(text, "showers") > strings.Count(text, "snow showers")
+	rain := strings.Contains(text, "rain") || showers || strings.Contains(text, "drizzle") || strings.Contains(text, "thunderstorm")
+	switch {
+	case nwsIcePattern.MatchString(text):
+		precipType = "Ice"
+	case strings.Contains(text, "wintry mix") || (snow && rain):
+		precipType = "Mixed"
+	case snow:
+		precipType = "Snow"
+	case rain:
+		precipType = "Rain"
+	default:
+		return "None", "None"
+	}
+
+	switch {
+	case strings.Contains(text, "heavy"):
+		intensity = "Heavy"
+	case strings.Contains(text, "light") || strings.Contains(text, "drizzle") || strings.Contains(text, "flurries"):
+		intensity = "Light"
+	default:
+		intensity = "Moderate"
+	}
+	return precipType, intensity
+}
@@ -0,0 +1,56 @@
+package service
+
+import "testing"
+
+func TestNWSPrecipitation(t *testing.T) {
+	tests := []struct {
+		shortForecast string
+		wantType      string
+		wantIntensity string
+	}{
+		{"Sunny", "None", "None"},
+		{"Freezing Fog", "None", "None"},
+		{"Patchy Freezing Fog then Mostly Sunny", "None", "None"},
+		{"Slight Chance Light Rain", "Rain", "Light"},
+		{"Chance Rain Showers", "Rain", "Moderate"},
+		{"Heavy Snow", "Snow", "Heavy"},
+		{"Snow Showers Likely", "Snow", "Moderate"},
+		{"Rain And Snow Showers", "Mixed", "Moderate"},
+		{"Wintry Mix", "Mixed", "Moderate"},
+		{"Freezing Rain", "Ice", "Moderate"},
+		{"Light Freezing Drizzle", "Ice", "Light"},
+		{"Sleet Likely", "Ice", "Moderate"},
+		{"Rain And Ice Pellets", "Ice", "Moderate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shortForecast, func(t *testing.T) {
+			gotType, gotIntensity := nwsPrecipitation(tt.shortForecast)
+			if gotType != tt.wantType || gotIntensity != tt.wantIntensity {
+				t.Errorf("nwsPrecipitation(%q) = %s, %s, want %s, %s", tt.shortForecast, gotType, gotIntensity, tt.wantType, tt.wantIntensity)
+			}
+		})
+	}
+}
+
+func TestNWSUserAgentContact(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		wantErr   bool
+	}{
+		{"(weather.example.com, ops@example.com)", false},
+		{"MyWeatherApp ops@example.com", false},
+		{"MyWeatherApp/1.0 (https://weather.example.com)", false},
+		{"", true},
+		{"MyWeatherApp", true},
+		{"MyWeatherApp/1.0", true},
+		{"ops@localhost", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			_, err := NewNWSService("", tt.userAgent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewNWSService(%q) error = %v, wantErr %t", tt.userAgent, err, tt.wantErr)
+			}
+		})
+	}
+}
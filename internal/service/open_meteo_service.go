@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// openMeteoLogger Open-Meteo服务的日志名称
+const openMeteoLogger = "openmeteo"
+
+// openMeteoProvider Open-Meteo数据源名称
+const openMeteoProvider = "openmeteo"
+
+// Open-Meteo默认接口地址
+const (
+	defaultOpenMeteoBaseURL      = "https://api.open-meteo.com"
+	defaultOpenMeteoGeocodingURL = "https://geocoding-api.open-meteo.com"
+)
+
+// openMeteoForecastHours 返回的逐小时预报数量
+const openMeteoForecastHours = 12
+
+// openMeteoForecastDays 返回的逐日预报天数
+const openMeteoForecastDays = 7
+
+func init() {
+	RegisterProvider(openMeteoProvider, func(cfg ProviderConfig) (Provider, error) {
+		// Open-Meteo不需要API密钥，接口地址可以指向兼容的自建服务
+		return NewOpenMeteoService(cfg.Getenv("OPENMETEO_BASE_URL"), cfg.Getenv("OPENMETEO_GEOCODING_URL")), nil
+	})
+}
+
+// openMeteoWeatherCode WMO天气代码对应的天气描述和降水信息
+type openMeteoWeatherCode struct {
+	Text      string
+	Type      string
+	Intensity string
+}
+
+// openMeteoWeatherCodes WMO天气代码表
+var openMeteoWeatherCodes = map[int]openMeteoWeatherCode{
+	0:  {"晴", "None", "None"},
+	1:  {"晴间多云", "None", "None"},
+	2:  {"多云", "None", "None"},
+	3:  {"阴", "None", "None"},
+	45: {"雾", "None", "None"},
+	48: {"冻雾", "None", "None"},
+	51: {"小毛毛雨", "Rain", "Light"},
+	53: {"毛毛雨", "Rain", "Moderate"},
+	55: {"大毛毛雨", "Rain", "Heavy"},
+	56: {"冻毛毛雨", "Ice", "Light"},
+	57: {"强冻毛毛雨", "Ice", "Heavy"},
+	61: {"小雨", "Rain", "Light"},
+	63: {"中雨", "Rain", "Moderate"},
+	65: {"大雨", "Rain", "Heavy"},
+	66: {"冻雨", "Ice", "Light"},
+	67: {"强冻雨", "Ice", "Heavy"},
+	71: {"小雪", "Snow", "Light"},
+	73: {"中雪", "Snow", "Moderate"},
+	75: {"大雪", "Snow", "Heavy"},
+	77: {"米雪", "Snow", "Light"},
+	80: {"小阵雨", "Rain", "Light"},
+	81: {"阵雨", "Rain", "Moderate"},
+	82: {"强阵雨", "Rain", "Heavy"},
+	85: {"阵雪", "Snow", "Light"},
+	86: {"强阵雪", "Snow", "Heavy"},
+	95: {"雷阵雨", "Rain", "Moderate"},
+	96: {"雷阵雨伴有冰雹", "Mixed", "Moderate"},
+	99: {"强雷阵雨伴有冰雹", "Mixed", "Heavy"},
+}
+
+// openMeteoLocation 解析后的地点
+type openMeteoLocation struct {
+	Name      string
+	Country   string
+	Latitude  float64
+	Longitude float64
+}
+
+// openMeteoService Open-Meteo天气服务实现
+type openMeteoService struct {
+	baseURL       string
+	geocodingURL  string
+	locationCache *cache.Cache
+}
+
+// NewOpenMeteoService 创建新的Open-Meteo天气服务，地址为空时使用官方接口
+func NewOpenMeteoService(baseURL, geocodingURL string) Provider {
+	if baseURL == "" {
+		baseURL = defaultOpenMeteoBaseURL
+	}
+	if geocodingURL == "" {
+		geocodingURL = defaultOpenMeteoGeocodingURL
+	}
+	return &openMeteoService{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		geocodingURL:  strings.TrimRight(geocodingURL, "/"),
+		locationCache: cache.New(24*time.Hour, 1*time.Hour),
+	}
+}
+
+// Name 返回数据源名称
+func (s *openMeteoService) Name() string {
+	return openMeteoProvider
+}
+
+// Capabilities 返回数据源能力，Open-Meteo提供全球范围的真实逐小时和逐日预报
+func (s *openMeteoService) Capabilities() bean.ProviderCapabilities {
+	return bean.ProviderCapabilities{
+		HourlyData: true,
+		DailyData:  true,
+		Coverage:   bean.ProviderCoverageGlobal,
+		Adcodes:    false,
+	}
+}
+
+// GetHourlyWeather 获取每小时天气预报，location可以是"纬度,经度"或地点名称
+func (s *openMeteoService) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	loc, err := s.resolveLocation(ctx, location)
+	if err != nil {
+		logging.Errorf(ctx, openMeteoLogger, "解析地点失败 %s: %v", location, err)
+		return nil, fmt.Errorf("解析地点失败: %w", err)
+	}
+
+	forecast, err := s.getForecast(ctx, loc.Latitude, loc.Longitude)
+	if err != nil {
+		logging.Errorf(ctx, openMeteoLogger, "获取天气预报失败 %s: %v", location, err)
+		return nil, fmt.Errorf("获取天气预报失败: %w", err)
+	}
+
+	current := forecast.Current
+	currentCode := openMeteoCode(current.WeatherCode)
+	return &bean.WeatherResponse{
+		Location:    loc.Name,
+		LocationKey: FormatCoordinates(forecast.Latitude, forecast.Longitude),
+		Country:     loc.Country,
+		CurrentConditions: bean.CurrentConditions{
+			Temperature: bean.Temperature{
+				Value: current.Temperature,
+				Unit:  "C",
+			},
+			WeatherText:      currentCode.Text,
+			RelativeHumidity: current.RelativeHumidity,
+			Precipitation:    current.Precipitation > 0 || currentCode.Type != "None",
+			ObservationTime:  current.Time,
+		},
+		HourlyForecast: s.formatHourlyForecast(forecast),
+		DailyForecast:  s.formatDailyForecast(forecast.Daily),
+		Provider:       openMeteoProvider,
+	}, nil
+}
+
+// resolveLocation 解析地点，坐标直接使用，名称通过地理编码接口查询
+func (s *openMeteoService) resolveLocation(ctx context.Context, location string) (*openMeteoLocation, error) {
+	if lat, lon, ok := parseCoordinates(location); ok {
+		return &openMeteoLocation{Name: FormatCoordinates(lat, lon), Latitude: lat, Longitude: lon}, nil
+	}
+
+	if cached, found := s.locationCache.Get(location); found {
+		logging.Debugf(ctx, openMeteoLogger, "命中地点缓存: %s", location)
+		return cached.(*openMeteoLocation), nil
+	}
+
+	params := url.Values{}
+	params.Add("name", location)
+	params.Add("count", "1")
+	params.Add("language", "zh")
+	params.Add("format", "json")
+
+	var geocoding bean.OpenMeteoGeocodingResponse
+	if err := s.getJSON(ctx, s.geocodingURL+"/v1/search?"+params.Encode(), &geocoding); err != nil {
+		return nil, err
+	}
+	if len(geocoding.Results) == 0 {
+		return nil, &bean.LocationNotFoundError{Location: location}
+	}
+
+	result := geocoding.Results[0]
+	loc := &openMeteoLocation{
+		Name:      result.Name,
+		Country:   result.Country,
+		Latitude:  result.Latitude,
+		Longitude: result.Longitude,
+	}
+	s.locationCache.Set(location, loc, cache.DefaultExpiration)
+	return loc, nil
+}
+
+// getForecast 获取当前天气、逐小时预报和逐日预报，逐小时预报多取一小时以便跳过当前小时
+func (s *openMeteoService) getForecast(ctx context.Context, lat, lon float64) (*bean.OpenMeteoForecastResponse, error) {
+	params := url.Values{}
+	params.Add("latitude", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Add("longitude", strconv.FormatFloat(lon, 'f', -1, 64))
+	params.Add("current", "temperature_2m,relative_humidity_2m,precipitation,weather_code")
+	params.Add("hourly", "temperature_2m,precipitation_probability,weather_code")
+	params.Add("forecast_hours", strconv.Itoa(openMeteoForecastHours+1))
+	params.Add("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum")
+	params.Add("forecast_days", strconv.Itoa(openMeteoForecastDays))
+	params.Add("timezone", "auto")
+
+	var forecast bean.OpenMeteoForecastResponse
+	if err := s.getJSON(ctx, s.baseURL+"/v1/forecast?"+params.Encode(), &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// getJSON 请求Open-Meteo接口并解析JSON响应
+func (s *openMeteoService) getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	logging.Infof(ctx, openMeteoLogger, "请求Open-Meteo接口: %s", rawURL)
+	resp, err := httpGet(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp bean.OpenMeteoErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Reason != "" {
+			return fmt.Errorf("API返回错误: %s", errResp.Reason)
+		}
+		return fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// formatHourlyForecast 格式化当前小时之后的逐小时预报
+func (s *openMeteoService) formatHourlyForecast(forecast *bean.OpenMeteoForecastResponse) []bean.HourlyForecast {
+	hourly := forecast.Hourly
+	result := make([]bean.HourlyForecast, 0, openMeteoForecastHours)
+	// timezone=auto时返回当地时间，按响应中的偏移补上时区
+	zone := time.FixedZone(forecast.Timezone, forecast.UTCOffset)
+
+	for i, t := range hourly.Time {
+		// 时间格式一致，可以直接比较字符串
+		if t <= forecast.Current.Time || i >= len(hourly.Temperature) || i >= len(hourly.WeatherCode) {
+			continue
+		}
+
+		code := openMeteoCode(hourly.WeatherCode[i])
+		probability := 0
+		if i < len(hourly.PrecipitationProbability) {
+			probability = hourly.PrecipitationProbability[i]
+		}
+
+		n := len(result) + 1
+		relativeTime := fmt.Sprintf("+%d hour", n)
+		if n > 1 {
+			relativeTime += "s"
+		}
+
+		var dateTime string
+		if local, err := time.ParseInLocation("2006-01-02T15:04", t, zone); err == nil {
+			dateTime = local.Format(time.RFC3339)
+		}
+
+		result = append(result, bean.HourlyForecast{
+			RelativeTime: relativeTime,
+			DateTime:     dateTime,
+			Temperature: bean.Temperature{
+				Value: hourly.Temperature[i],
+				Unit:  "C",
+			},
+			WeatherText:              code.Text,
+			PrecipitationProbability: probability,
+			PrecipitationType:        code.Type,
+			PrecipitationIntensity:   code.Intensity,
+		})
+		if len(result) >= openMeteoForecastHours {
+			break
+		}
+	}
+
+	return result
+}
+
+// formatDailyForecast 格式化逐日预报，Open-Meteo每天只有一个天气代码，白天和夜间天气相同
+func (s *openMeteoService) formatDailyForecast(daily bean.OpenMeteoDaily) []bean.DailyForecast {
+	result := make([]bean.DailyForecast, 0, len(daily.Time))
+	for i, date := range daily.Time {
+		if i >= len(daily.WeatherCode) || i >= len(daily.TemperatureMax) || i >= len(daily.TemperatureMin) {
+			break
+		}
+
+		var precipitation float64
+		if i < len(daily.PrecipitationSum) {
+			precipitation = daily.PrecipitationSum[i]
+		}
+		text := openMeteoCode(daily.WeatherCode[i]).Text
+		result = append(result, bean.DailyForecast{
+			Date:             date,
+			TempMax:          bean.Temperature{Value: daily.TemperatureMax[i], Unit: "C"},
+			TempMin:          bean.Temperature{Value: daily.TemperatureMin[i], Unit: "C"},
+			WeatherTextDay:   text,
+			WeatherTextNight: text,
+			Precipitation:    precipitation,
+		})
+	}
+	return result
+}
+
+// openMeteoCode 查询WMO天气代码，未知代码只给出代码本身
+func openMeteoCode(code int) openMeteoWeatherCode {
+	if c, found := openMeteoWeatherCodes[code]; found {
+		return c
+	}
+	return openMeteoWeatherCode{Text: fmt.Sprintf("天气代码%d", code), Type: "None", Intensity: "None"}
+}
+
+// parseCoordinates 解析"纬度,经度"格式的坐标
+func parseCoordinates(location string) (lat, lon float64, ok bool) {
+	latStr, lonStr, found := strings.Cut(location, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lon, err = strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// FormatCoordinates 将坐标格式化为"纬度,经度"，保留4位小数，按坐标查询时作为地点传给数据源
+func FormatCoordinates(lat, lon float64) string {
+	return strconv.FormatFloat(lat, 'f', 4, 64) + "," + strconv.FormatFloat(lon, 'f', 4, 64)
+}
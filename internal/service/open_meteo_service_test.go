@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newOpenMeteoTestServer 模拟Open-Meteo预报接口，当前时间为2024-01-01T08:00（UTC+8），
+// 逐小时预报从当前小时开始共13小时，第二个小时起下小雨
+func newOpenMeteoTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	hourly := map[string]interface{}{
+		"time":                      []string{},
+		"temperature_2m":            []float64{},
+		"precipitation_probability": []int{},
+		"weather_code":              []int{},
+	}
+	for i := 0; i < 13; i++ {
+		code := 0
+		if i >= 2 {
+			code = 61
+		}
+		hourly["time"] = append(hourly["time"].([]string), fmt.Sprintf("2024-01-01T%02d:00", 8+i))
+		hourly["temperature_2m"] = append(hourly["temperature_2m"].([]float64), float64(i))
+		hourly["precipitation_probability"] = append(hourly["precipitation_probability"].([]int), i*5)
+		hourly["weather_code"] = append(hourly["weather_code"].([]int), code)
+	}
+	body := map[string]interface{}{
+		"latitude":           39.9,
+		"longitude":          116.4,
+		"timezone":           "Asia/Shanghai",
+		"utc_offset_seconds": 8 * 3600,
+		"current": map[string]interface{}{
+			"time":                 "2024-01-01T08:00",
+			"temperature_2m":       -1.5,
+			"relative_humidity_2m": 40,
+			"precipitation":        0,
+			"weather_code":         3,
+		},
+		"hourly": hourly,
+		"daily": map[string]interface{}{
+			"time":               []string{"2024-01-01", "2024-01-02"},
+			"weather_code":       []int{3, 61},
+			"temperature_2m_max": []float64{5, 3},
+			"temperature_2m_min": []float64{-4, -2},
+			"precipitation_sum":  []float64{0, 2.4},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/forecast" || !strings.Contains(r.URL.Query().Get("daily"), "temperature_2m_max") {
+			http.Error(w, `{"error":true,"reason":"unexpected request"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenMeteoHourlyForecast(t *testing.T) {
+	server := newOpenMeteoTestServer(t)
+	s := NewOpenMeteoService(server.URL, server.URL)
+
+	response, err := s.GetHourlyWeather(context.Background(), "39.9,116.4")
+	if err != nil {
+		t.Fatalf("GetHourlyWeather() error = %v", err)
+	}
+
+	if got := response.CurrentConditions; got.WeatherText != "阴" || got.Temperature.Value != -1.5 || got.RelativeHumidity != 40 {
+		t.Errorf("CurrentConditions = %+v", got)
+	}
+	if len(response.HourlyForecast) != openMeteoForecastHours {
+		t.Fatalf("len(HourlyForecast) = %d, want %d", len(response.HourlyForecast), openMeteoForecastHours)
+	}
+
+	// 当前小时被跳过，预报从下一小时开始
+	first := response.HourlyForecast[0]
+	if first.RelativeTime != "+1 hour" || first.DateTime != "2024-01-01T09:00:00+08:00" || first.Temperature.Value != 1 {
+		t.Errorf("HourlyForecast[0] = %+v", first)
+	}
+	if first.PrecipitationType != "None" || first.PrecipitationProbability != 5 {
+		t.Errorf("HourlyForecast[0] precipitation = %s %d%%", first.PrecipitationType, first.PrecipitationProbability)
+	}
+	second := response.HourlyForecast[1]
+	if second.RelativeTime != "+2 hours" || second.WeatherText != "小雨" || second.PrecipitationType != "Rain" || second.PrecipitationIntensity != "Light" {
+		t.Errorf("HourlyForecast[1] = %+v", second)
+	}
+
+	if len(response.DailyForecast) != 2 {
+		t.Fatalf("len(DailyForecast) = %d, want 2", len(response.DailyForecast))
+	}
+	if day := response.DailyForecast[1]; day.Date != "2024-01-02" || day.WeatherTextDay != "小雨" || day.TempMax.Value != 3 || day.TempMin.Value != -2 || day.Precipitation != 2.4 {
+		t.Errorf("DailyForecast[1] = %+v", day)
+	}
+}
+
+func TestOpenMeteoErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"带原因", http.StatusBadRequest, `{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`, "API返回错误: Latitude must be in range"},
+		{"无原因", http.StatusBadGateway, `bad gateway`, "API请求失败，状态码: 502"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := NewOpenMeteoService(server.URL, server.URL)
+			_, err := s.GetHourlyWeather(context.Background(), "39.9,116.4")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetHourlyWeather() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tung/mcp/internal/bean"
+)
+
+// DefaultProvider 未配置WEATHER_PROVIDER时使用的数据源
+const DefaultProvider = amapProvider
+
+// Provider 天气数据源接口
+type Provider interface {
+	Name() string
+	Capabilities() bean.ProviderCapabilities
+	GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error)
+}
+
+// ProviderConfig 创建数据源时可用的配置和依赖
+type ProviderConfig struct {
+	Getenv          func(key string) string
+	DistrictService DistrictService
+	Geocoder        AmapGeocoder // 共享的高德地理编码服务，为空时高德数据源自行创建
+}
+
+// ProviderFactory 数据源的创建函数
+type ProviderFactory func(cfg ProviderConfig) (Provider, error)
+
+// providerRegistry 已注册的数据源
+var providerRegistry = struct {
+	sync.RWMutex
+	factories map[string]ProviderFactory
+}{factories: make(map[string]ProviderFactory)}
+
+// RegisterProvider 注册数据源，通常在数据源文件的init中调用
+func RegisterProvider(name string, factory ProviderFactory) {
+	providerRegistry.Lock()
+	defer providerRegistry.Unlock()
+	providerRegistry.factories[name] = factory
+}
+
+// ProviderNames 按字母顺序返回所有已注册的数据源名称
+func ProviderNames() []string {
+	providerRegistry.RLock()
+	defer providerRegistry.RUnlock()
+	names := make([]string, 0, len(providerRegistry.factories))
+	for name := range providerRegistry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider 按名称创建数据源
+func NewProvider(name string, cfg ProviderConfig) (Provider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	providerRegistry.RLock()
+	factory, found := providerRegistry.factories[name]
+	providerRegistry.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("未知的天气数据源: %s，可选: %s", name, strings.Join(ProviderNames(), "、"))
+	}
+
+	provider, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建天气数据源%s失败: %w", name, err)
+	}
+	// 不认区域编码的数据源借助高德地理编码把区域编码换成坐标
+	if !provider.Capabilities().Adcodes && cfg.Geocoder != nil && cfg.DistrictService != nil {
+		provider = NewAdcodeProvider(provider, cfg.DistrictService, cfg.Geocoder)
+	}
+	return provider, nil
+}
+
+// forecastTimeLayouts 上游接口预报时间的格式，和风天气的时间不带秒
+var forecastTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00"}
+
+// forecastDateTime 将上游接口带时区的预报时间统一为RFC 3339格式，无法解析时返回空字符串
+func forecastDateTime(value string) string {
+	for _, layout := range forecastTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// coverageUnion 合并多个数据源的覆盖范围：任一为global时为global，
+// 否则为各地区按字母顺序以逗号连接，例如china,us
+func coverageUnion(coverages ...string) string {
+	regions := make(map[string]struct{})
+	for _, coverage := range coverages {
+		for _, region := range strings.Split(coverage, ",") {
+			if region == bean.ProviderCoverageGlobal {
+				return bean.ProviderCoverageGlobal
+			}
+			if region != "" {
+				regions[region] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(regions))
+	for region := range regions {
+		names = append(names, region)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
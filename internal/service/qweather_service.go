@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/tung/mcp/internal/bean"
+	"github.com/tung/mcp/internal/logging"
+)
+
+// qweatherLogger 和风天气服务的日志名称
+const qweatherLogger = "qweather"
+
+// qweatherProvider 和风天气数据源名称
+const qweatherProvider = "qweather"
+
+// 和风天气默认接口地址，付费订阅或独立API Host可通过环境变量覆盖
+const (
+	defaultQWeatherAPIHost = "https://devapi.qweather.com"
+	defaultQWeatherGeoHost = "https://geoapi.qweather.com"
+)
+
+// qweatherHourlyForecasts 返回的逐小时预报数量
+const qweatherHourlyForecasts = 24
+
+func init() {
+	RegisterProvider(qweatherProvider, func(cfg ProviderConfig) (Provider, error) {
+		apiKey := cfg.Getenv("QWEATHER_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("未设置QWEATHER_API_KEY环境变量")
+		}
+		return NewQWeatherService(apiKey, cfg.Getenv("QWEATHER_API_HOST"), cfg.Getenv("QWEATHER_GEO_HOST"), cfg.DistrictService), nil
+	})
+}
+
+// qweatherLocation 和风天气城市，作为地点缓存的值
+type qweatherLocation struct {
+	ID      string
+	Name    string
+	Country string
+}
+
+// qweatherService 和风天气服务实现
+type qweatherService struct {
+	apiKey          string
+	apiHost         string
+	geoHost         string
+	districtService DistrictService
+	locationCache   *cache.Cache
+}
+
+// NewQWeatherService 创建新的和风天气服务，接口地址为空时使用默认地址
+func NewQWeatherService(apiKey, apiHost, geoHost string, districtService DistrictService) Provider {
+	if apiHost == "" {
+		apiHost = defaultQWeatherAPIHost
+	}
+	if geoHost == "" {
+		geoHost = defaultQWeatherGeoHost
+	}
+	return &qweatherService{
+		apiKey:          apiKey,
+		apiHost:         strings.TrimRight(apiHost, "/"),
+		geoHost:         strings.TrimRight(geoHost, "/"),
+		districtService: districtService,
+		locationCache:   cache.New(24*time.Hour, 1*time.Hour),
+	}
+}
+
+// Name 返回数据源名称
+func (s *qweatherService) Name() string {
+	return qweatherProvider
+}
+
+// Capabilities 返回数据源能力，和风天气提供真实的逐小时和逐日预报以及官方预警
+func (s *qweatherService) Capabilities() bean.ProviderCapabilities {
+	return bean.ProviderCapabilities{
+		HourlyData: true,
+		DailyData:  true,
+		Coverage:   bean.ProviderCoverageGlobal,
+		Adcodes:    true,
+	}
+}
+
+// GetHourlyWeather 获取实时天气、24小时逐小时预报、7天逐日预报和天气预警，
+// 地点的写法与高德数据源相同
+func (s *qweatherService) GetHourlyWeather(ctx context.Context, location string) (*bean.WeatherResponse, error) {
+	loc, err := s.resolveLocation(ctx, location)
+	if err != nil {
+		logging.Errorf(ctx, qweatherLogger, "查询城市失败 %s: %v", location, err)
+		return nil, fmt.Errorf("查询城市失败: %w", err)
+	}
+
+	var now bean.QWeatherNowResponse
+	if err := s.getJSON(ctx, s.apiHost+"/v7/weather/now", loc.ID, &now); err != nil {
+		logging.Errorf(ctx, qweatherLogger, "获取实时天气失败 %s: %v", loc.ID, err)
+		return nil, fmt.Errorf("获取实时天气失败: %w", err)
+	}
+
+	var hourly bean.QWeatherHourlyResponse
+	if err := s.getJSON(ctx, s.apiHost+"/v7/weather/24h", loc.ID, &hourly); err != nil {
+		logging.Errorf(ctx, qweatherLogger, "获取逐小时预报失败 %s: %v", loc.ID, err)
+		return nil, fmt.Errorf("获取逐小时预报失败: %w", err)
+	}
+
+	// 逐日预报和预警是补充信息，失败时只记录日志
+	var daily bean.QWeatherDailyResponse
+	if err := s.getJSON(ctx, s.apiHost+"/v7/weather/7d", loc.ID, &daily); err != nil {
+		logging.Warningf(ctx, qweatherLogger, "获取逐日预报失败 %s: %v", loc.ID, err)
+	}
+	var warning bean.QWeatherWarningResponse
+	if err := s.getJSON(ctx, s.apiHost+"/v7/warning/now", loc.ID, &warning); err != nil {
+		logging.Warningf(ctx, qweatherLogger, "获取天气预警失败 %s: %v", loc.ID, err)
+	}
+
+	precipType, _ := qweatherPrecipitation(now.Now.Icon)
+	precip, _ := strconv.ParseFloat(now.Now.Precip, 64)
+	return &bean.WeatherResponse{
+		Location:    loc.Name,
+		LocationKey: loc.ID,
+		Country:     loc.Country,
+		CurrentConditions: bean.CurrentConditions{
+			Temperature:      qweatherTemperature(now.Now.Temp),
+			WeatherText:      now.Now.Text,
+			RelativeHumidity: atoiOrZero(now.Now.Humidity),
+			Precipitation:    precip > 0 || precipType != "None",
+			ObservationTime:  now.Now.ObsTime,
+		},
+		HourlyForecast: s.formatHourlyForecast(hourly.Hourly),
+		ForecastTime:   hourly.UpdateTime,
+		DailyForecast:  s.formatDailyForecast(daily.Daily),
+		Warnings:       s.formatWarnings(warning.Warning),
+		Provider:       qweatherProvider,
+	}, nil
+}
+
+// resolveLocation 将地点解析为和风天气的LocationID。区域编码或能唯一确定的区划名称
+// 先转换为区域编码，坐标按"经度,纬度"查询，其余按名称查询
+func (s *qweatherService) resolveLocation(ctx context.Context, location string) (*qweatherLocation, error) {
+	query := location
+	if adcode, found := s.districtService.ResolveAdcode(location); found {
+		query = adcode
+	} else if candidates := s.districtService.Match(location); len(candidates) > 1 {
+		return nil, &bean.AmbiguousLocationError{Location: location, Candidates: candidates}
+	} else if lat, lon, ok := parseCoordinates(location); ok {
+		// 和风天气在中国大陆使用GCJ-02坐标，境外与WGS-84相同
+		lat, lon = ConvertCoordinates(lat, lon, bean.CRSWGS84, bean.CRSGCJ02)
+		query = strconv.FormatFloat(lon, 'f', 2, 64) + "," + strconv.FormatFloat(lat, 'f', 2, 64)
+	}
+
+	if cached, found := s.locationCache.Get(query); found {
+		logging.Debugf(ctx, qweatherLogger, "命中城市缓存: %s", query)
+		return cached.(*qweatherLocation), nil
+	}
+
+	var lookup bean.QWeatherCityLookupResponse
+	if err := s.getJSON(ctx, s.geoHost+"/v2/city/lookup", query, &lookup); err != nil {
+		return nil, err
+	}
+	if len(lookup.Location) == 0 {
+		return nil, &bean.LocationNotFoundError{Location: location}
+	}
+
+	city := lookup.Location[0]
+	loc := &qweatherLocation{ID: city.ID, Name: city.Name, Country: city.Country}
+	s.locationCache.Set(query, loc, cache.DefaultExpiration)
+	return loc, nil
+}
+
+// getJSON 请求和风天气接口并解析JSON响应，接口返回的code不为200时返回错误，
+// 404表示找不到地点
+func (s *qweatherService) getJSON(ctx context.Context, endpoint, location string, out interface{}) error {
+	params := url.Values{}
+	params.Add("location", location)
+	params.Add("key", s.apiKey)
+	params.Add("lang", "zh")
+	rawURL := endpoint + "?" + params.Encode()
+
+	logging.Infof(ctx, qweatherLogger, "请求和风天气接口: %s", redactAPIKey(rawURL))
+	resp, err := httpGet(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var status struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return err
+	}
+	if status.Code == "404" {
+		return &bean.LocationNotFoundError{Location: location}
+	}
+	if status.Code != "200" {
+		return fmt.Errorf("API返回错误: %s", status.Code)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// formatHourlyForecast 格式化逐小时预报
+func (s *qweatherService) formatHourlyForecast(hourly []bean.QWeatherHourly) []bean.HourlyForecast {
+	result := make([]bean.HourlyForecast, 0, qweatherHourlyForecasts)
+	for i, hour := range hourly {
+		if i >= qweatherHourlyForecasts {
+			break
+		}
+
+		relativeTime := fmt.Sprintf("+%d hour", i+1)
+		if i > 0 {
+			relativeTime += "s"
+		}
+
+		precipType, precipIntensity := qweatherPrecipitation(hour.Icon)
+		result = append(result, bean.HourlyForecast{
+			RelativeTime:             relativeTime,
+			DateTime:                 forecastDateTime(hour.FxTime),
+			Temperature:              qweatherTemperature(hour.Temp),
+			WeatherText:              hour.Text,
+			PrecipitationProbability: atoiOrZero(hour.Pop),
+			PrecipitationType:        precipType,
+			PrecipitationIntensity:   precipIntensity,
+		})
+	}
+	return result
+}
+
+// formatDailyForecast 格式化逐日预报
+func (s *qweatherService) formatDailyForecast(daily []bean.QWeatherDaily) []bean.DailyForecast {
+	result := make([]bean.DailyForecast, 0, len(daily))
+	for _, day := range daily {
+		precip, _ := strconv.ParseFloat(day.Precip, 64)
+		result = append(result, bean.DailyForecast{
+			Date:             day.FxDate,
+			TempMax:          qweatherTemperature(day.TempMax),
+			TempMin:          qweatherTemperature(day.TempMin),
+			WeatherTextDay:   day.TextDay,
+			WeatherTextNight: day.TextNight,
+			Precipitation:    precip,
+			RelativeHumidity: atoiOrZero(day.Humidity),
+		})
+	}
+	return result
+}
+
+// formatWarnings 格式化天气预警
+func (s *qweatherService) formatWarnings(warnings []bean.QWeatherWarning) []bean.WeatherWarning {
+	result := make([]bean.WeatherWarning, 0, len(warnings))
+	for _, w := range warnings {
+		result = append(result, bean.WeatherWarning{
+			ID:        w.ID,
+			Sender:    w.Sender,
+			PubTime:   w.PubTime,
+			Title:     w.Title,
+			StartTime: w.StartTime,
+			EndTime:   w.EndTime,
+			Status:    w.Status,
+			Severity:  w.Severity,
+			Color:     w.SeverityColor,
+			Type:      w.Type,
+			TypeName:  w.TypeName,
+			Text:      w.Text,
+		})
+	}
+	return result
+}
+
+// qweatherTemperature 转换摄氏温度
+func qweatherTemperature(value string) bean.Temperature {
+	temp, _ := strconv.ParseFloat(value, 64)
+	return bean.Temperature{Value: temp, Unit: "C"}
+}
+
+// qweatherPrecipitation 根据和风天气图标代码判断降水类型和强度，
+// 3xx为降雨，4xx为降雪，其余为无降水
+func qweatherPrecipitation(icon string) (precipType, intensity string) {
+	code, err := strconv.Atoi(icon)
+	if err != nil {
+		return "None", "None"
+	}
+
+	switch code {
+	case 300, 305, 309, 350, 400, 407, 456:
+		intensity = "Light"
+	case 301, 307, 308, 310, 311, 312, 316, 317, 318, 351, 402, 403, 410, 457:
+		intensity = "Heavy"
+	default:
+		intensity = "Moderate"
+	}
+
+	switch {
+	case code == 313:
+		return "Ice", intensity
+	case code >= 300 && code < 400:
+		return "Rain", intensity
+	case code >= 404 && code <= 406 || code == 456:
+		return "Mixed", intensity
+	case code >= 400 && code < 500:
+		return "Snow", intensity
+	}
+	return "None", "None"
+}
+
+// atoiOrZero 解析整数，空字符串或无效值返回0
+func atoiOrZero(value string) int {
+	n, _ := strconv.Atoi(value)
+	return n
+}
@@ -1,41 +1,218 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/tung/mcp/internal/auth"
 	"github.com/tung/mcp/internal/handler"
+	"github.com/tung/mcp/internal/logging"
 	"github.com/tung/mcp/internal/logic"
 	"github.com/tung/mcp/internal/service"
 )
 
+// serverLogger 服务启动日志的名称
+const serverLogger = "server"
+
+// shutdownTimeout 服务关闭时等待进行中请求的最长时间
+const shutdownTimeout = 5 * time.Second
+
 func main() {
+	transport := flag.String("transport", "", "运行模式: http 或 stdio，默认读取MCP_TRANSPORT环境变量")
+	flag.Parse()
+
 	// 加载环境变量
 	if err := godotenv.Load(); err != nil {
-		log.Println("警告: 未找到.env文件，将使用系统环境变量")
+		logging.Warningf(context.Background(), serverLogger, "未找到.env文件，将使用系统环境变量")
+	}
+
+	// 设置写入标准错误输出的日志级别
+	if name := os.Getenv("LOG_LEVEL"); name != "" {
+		level, ok := logging.ParseLevel(name)
+		if !ok {
+			logging.Fatalf(context.Background(), serverLogger, "未知的LOG_LEVEL: %s", name)
+		}
+		logging.SetLevel(level)
+	}
+
+	// 录制或回放上游天气接口的响应，回放时不需要网络和真实的API密钥
+	getenv := os.Getenv
+	fixturesDir := os.Getenv("UPSTREAM_FIXTURES_DIR")
+	if fixturesDir == "" {
+		fixturesDir = "fixtures"
 	}
+	switch mode := os.Getenv("UPSTREAM_MODE"); mode {
+	case "", service.UpstreamLive:
+	case service.UpstreamRecord:
+		service.SetUpstreamTransport(service.NewRecordingTransport(fixturesDir, nil))
+		logging.Infof(context.Background(), serverLogger, "录制上游响应到 %s", fixturesDir)
+	case service.UpstreamReplay:
+		var replayOptions service.ReplayOptions
+		if value := os.Getenv("UPSTREAM_REPLAY_LATENCY"); value != "" {
+			latency, err := time.ParseDuration(value)
+			if err != nil || latency < 0 {
+				logging.Fatalf(context.Background(), serverLogger, "无效的UPSTREAM_REPLAY_LATENCY: %s", value)
+			}
+			replayOptions.Latency = latency
+		}
+		for _, host := range strings.Split(os.Getenv("UPSTREAM_REPLAY_FAIL_HOSTS"), ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				replayOptions.FailHosts = append(replayOptions.FailHosts, host)
+			}
+		}
+		service.SetUpstreamTransport(service.NewReplayTransport(fixturesDir, replayOptions))
+		logging.Infof(context.Background(), serverLogger, "从 %s 回放上游响应", fixturesDir)
 
-	// 获取API密钥
-	apiKey := os.Getenv("AMAP_API_KEY")
-	if apiKey == "" {
-		log.Fatal("错误: 未设置AMAP_API_KEY环境变量")
+		// 录制文件不包含密钥，未设置的API密钥和NWS的User-Agent用占位值代替
+		getenv = func(key string) string {
+			value := os.Getenv(key)
+			switch {
+			case value != "":
+				return value
+			case strings.HasSuffix(key, "_API_KEY"):
+				return "replay"
+			case key == "NWS_USER_AGENT":
+				return "(replay, replay@example.com)"
+			}
+			return value
+		}
+	default:
+		logging.Fatalf(context.Background(), serverLogger, "未知的UPSTREAM_MODE: %s，可选: %s、%s、%s", mode, service.UpstreamLive, service.UpstreamRecord, service.UpstreamReplay)
 	}
 
 	// 创建服务
-	weatherService := service.NewAmapWeatherService(apiKey)
-	weatherLogic := logic.NewWeatherLogic(weatherService)
+	districtService := service.NewDistrictService()
+
+	// 设置AMAP_API_KEY时按坐标查询的响应附上所在行政区划，高德数据源共用同一个地理编码服务的缓存
+	var geocoder service.AmapGeocoder
+	if apiKey := getenv("AMAP_API_KEY"); apiKey != "" {
+		geocoder = service.NewAmapGeocoder(apiKey)
+	}
+
+	// 按WEATHER_PROVIDER选择天气数据源，各数据源自行读取所需的API密钥；
+	// 以逗号分隔多个数据源时按顺序降级
+	providerNames := os.Getenv("WEATHER_PROVIDER")
+	if providerNames == "" {
+		providerNames = service.DefaultProvider
+	}
+	var fallbackOptions service.FallbackOptions
+	if value := os.Getenv("WEATHER_BREAKER_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold <= 0 {
+			logging.Fatalf(context.Background(), serverLogger, "无效的WEATHER_BREAKER_THRESHOLD: %s", value)
+		}
+		fallbackOptions.FailureThreshold = threshold
+	}
+	if value := os.Getenv("WEATHER_BREAKER_COOLDOWN"); value != "" {
+		cooldown, err := time.ParseDuration(value)
+		if err != nil || cooldown <= 0 {
+			logging.Fatalf(context.Background(), serverLogger, "无效的WEATHER_BREAKER_COOLDOWN: %s", value)
+		}
+		fallbackOptions.Cooldown = cooldown
+	}
+	weatherService, err := service.NewProviderChain(strings.Split(providerNames, ","), service.ProviderConfig{
+		Getenv:          getenv,
+		DistrictService: districtService,
+		Geocoder:        geocoder,
+	}, fallbackOptions)
+	if err != nil {
+		logging.Fatalf(context.Background(), serverLogger, "%v", err)
+	}
+	caps := weatherService.Capabilities()
+	logging.Infof(context.Background(), serverLogger, "天气数据源: %s（逐小时预报: %t，逐日预报: %t，覆盖范围: %s，区域编码: %t）",
+		weatherService.Name(), caps.HourlyData, caps.DailyData, caps.Coverage, caps.Adcodes)
+	// WEATHER_IP_LOCATION=true时省略地点的请求按客户端IP所在城市查询，需要高德IP定位接口
+	var ipLocator service.IPLocator
+	if enabled, _ := strconv.ParseBool(os.Getenv("WEATHER_IP_LOCATION")); enabled {
+		if geocoder == nil {
+			logging.Fatalf(context.Background(), serverLogger, "启用WEATHER_IP_LOCATION时必须设置AMAP_API_KEY环境变量")
+		}
+		ipLocator = service.NewAmapIPLocator(getenv("AMAP_API_KEY"), os.Getenv("AMAP_IP_URL"), districtService)
+		logging.Infof(context.Background(), serverLogger, "已启用按客户端IP定位")
+	}
+	weatherLogic := logic.NewWeatherLogic(weatherService, geocoder, ipLocator)
+	locationLogic := logic.NewLocationLogic(districtService)
 	weatherHandler := handler.NewWeatherHandler(weatherLogic)
 
+	// 设置CAIYUN_API_KEY后提供分钟级短时降水预报
+	var nowcastLogic logic.NowcastLogic
+	if apiKey := getenv("CAIYUN_API_KEY"); apiKey != "" {
+		nowcastLogic = logic.NewNowcastLogic(service.NewCaiyunNowcastService(apiKey, os.Getenv("CAIYUN_BASE_URL")))
+	}
+
 	// 创建MCP处理器
-	mcpHandler := handler.NewMCPHandler(weatherLogic)
+	mcpHandler := handler.NewMCPHandler(weatherLogic, locationLogic, nowcastLogic)
+	defer mcpHandler.Close()
+
+	if *transport == "" {
+		*transport = os.Getenv("MCP_TRANSPORT")
+	}
+
+	// stdio模式下标准输出用于传输MCP消息，日志只能写到标准错误
+	if *transport == "stdio" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		logging.Infof(ctx, serverLogger, "MCP天气服务以stdio模式启动")
+		if err := mcpHandler.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+			logging.Fatalf(ctx, serverLogger, "stdio服务异常退出: %v", err)
+		}
+		return
+	}
 
 	// 创建Gin路由
 	router := gin.Default()
 
+	// 只采用受信任代理转发的X-Forwarded-For，未配置时直接使用连接的对端地址。
+	// gin默认还会采用X-Real-IP，代理通常不会清除客户端伪造的该请求头
+	router.RemoteIPHeaders = []string{"X-Forwarded-For"}
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logging.Fatalf(context.Background(), serverLogger, "无效的TRUSTED_PROXIES: %v", err)
+	}
+
+	// 配置了JWKS时作为OAuth资源服务器，之后注册的路由都需要访问令牌
+	if jwks := os.Getenv("OAUTH_JWKS"); jwks != "" {
+		resource := os.Getenv("OAUTH_RESOURCE")
+		if resource == "" {
+			logging.Fatalf(context.Background(), serverLogger, "启用OAuth时必须设置OAUTH_RESOURCE环境变量")
+		}
+		audience := os.Getenv("OAUTH_AUDIENCE")
+		if audience == "" {
+			audience = resource
+		}
+		issuer := os.Getenv("OAUTH_ISSUER")
+
+		authHandler := handler.NewAuthHandler(auth.NewVerifier(issuer, audience, jwks), resource, issuer)
+		authHandler.RegisterRoutes(router)
+		router.Use(authHandler.Authenticate())
+		logging.Infof(context.Background(), serverLogger, "已启用OAuth鉴权，资源标识: %s", resource)
+	}
+
 	// 注册路由
 	weatherHandler.RegisterRoutes(router)
+	// 搜索结果以区域编码指代地点，数据源不能按区域编码查询时不提供
+	if caps.Adcodes {
+		handler.NewLocationHandler(locationLogic).RegisterRoutes(router)
+	}
+	if nowcastLogic != nil {
+		handler.NewNowcastHandler(nowcastLogic).RegisterRoutes(router)
+	}
 	mcpHandler.RegisterRoutes(router)
 
 	// 启动服务器
@@ -44,11 +221,26 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("MCP天气服务启动在 :%s", port)
-	log.Printf("标准API路径: http://localhost:%s/weather", port)
-	log.Printf("Claude MCP API路径: http://localhost:%s/mcp", port)
+	logging.Infof(context.Background(), serverLogger, "MCP天气服务启动在 :%s", port)
+	logging.Infof(context.Background(), serverLogger, "标准API路径: http://localhost:%s/weather", port)
+	logging.Infof(context.Background(), serverLogger, "Claude MCP API路径: http://localhost:%s/mcp", port)
+
+	// 收到退出信号后停止接收新连接，SSE等长连接最多等待shutdownTimeout
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			server.Close()
+		}
+	}()
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("服务器启动失败: %v", err)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logging.Fatalf(context.Background(), serverLogger, "服务器启动失败: %v", err)
 	}
+	logging.Infof(context.Background(), serverLogger, "MCP天气服务已停止")
 }